@@ -0,0 +1,234 @@
+// Command hhclub lists, renders and scaffolds the shipped CI/CD templates.
+//
+//	hhclub list
+//	hhclub render <template> [--var KEY=VALUE]... [--vars-file vars.yaml] [-o out]
+//	hhclub scaffold --out ./myproj [--template NAME]... [--var KEY=VALUE]...
+//	hhclub lint
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/loufp/hhClub/render"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "hhclub:", err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("usage: hhclub <list|render|scaffold|lint> [flags]")
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "list":
+		return list(stdout)
+	case "render":
+		return renderCmd(args[1:], stdout, stderr)
+	case "scaffold":
+		return scaffold(args[1:], stdout, stderr)
+	case "lint":
+		return lint(stdout)
+	case "help", "-h", "--help":
+		fmt.Fprintln(stdout, errUsage)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q: %w", args[0], errUsage)
+}
+
+func list(stdout io.Writer) error {
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tCATEGORY\tVARIABLES")
+	for _, t := range render.ListTemplates() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", t.Name, t.Category, strings.Join(t.Tokens, ", "))
+	}
+	return tw.Flush()
+}
+
+func renderCmd(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var v varFlags
+	fs.Var(&v.vars, "var", "set a variable as KEY=VALUE (repeatable, wins over --vars-file)")
+	fs.StringVar(&v.file, "vars-file", "", "load variables from a .yaml, .yml or .json file; vars.<env>.yaml layers over vars.yaml")
+	out := fs.String("o", "", "write to this file instead of stdout")
+	overwrite := fs.Bool("overwrite", false, "replace an existing output file")
+
+	// Accept the template name before or after the flags.
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if name == "" && fs.NArg() > 0 {
+		// Flags after a name that follows other flags still count.
+		name = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return errUsage
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("render: missing template name: %w", errUsage)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("render: unexpected argument %q: %w", fs.Arg(0), errUsage)
+	}
+
+	vars, err := v.load(nil)
+	if err != nil {
+		return err
+	}
+	if err := checkMissing([]string{name}, vars); err != nil {
+		return err
+	}
+
+	if *out != "" {
+		err = render.WriteTemplate(name, *out, vars, *overwrite)
+	} else {
+		var s string
+		s, err = render.RenderTemplate(name, vars)
+		if s != "" {
+			fmt.Fprint(stdout, s)
+		}
+	}
+	return warnUnknown(err, stderr)
+}
+
+func scaffold(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("scaffold", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var v varFlags
+	fs.Var(&v.vars, "var", "set a variable as KEY=VALUE (repeatable, wins over --vars-file)")
+	fs.StringVar(&v.file, "vars-file", "", "load variables from a .yaml, .yml or .json file; vars.<env>.yaml layers over vars.yaml")
+	var templates multiFlag
+	fs.Var(&templates, "template", "template to render (repeatable, default docker/Dockerfile and jenkins/Jenkinsfile)")
+	outDir := fs.String("out", "", "project directory to write into")
+	module := fs.String("module", ".", "Go module to infer BUILD_CMD, TEST_CMD and IMAGE_NAME from")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if *outDir == "" {
+		return fmt.Errorf("scaffold: --out is required: %w", errUsage)
+	}
+	if len(templates) == 0 {
+		templates = multiFlag{"docker/Dockerfile", "jenkins/Jenkinsfile"}
+	}
+
+	inferred, err := render.InferGoVars(*module)
+	if err != nil {
+		return err
+	}
+	vars, err := v.load(inferred)
+	if err != nil {
+		return err
+	}
+	if err := checkMissing(templates, vars); err != nil {
+		return err
+	}
+
+	written, err := render.RenderAll(*outDir, templates, vars)
+	for _, p := range written {
+		fmt.Fprintln(stdout, p)
+	}
+	return warnUnknown(err, stderr)
+}
+
+// lint renders every shipped template and reports invalid output, failing
+// when any template has issues.
+func lint(stdout io.Writer) error {
+	results, err := render.LintAll()
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		fmt.Fprintf(stdout, "%s:\n", r.Template)
+		for _, issue := range r.Issues {
+			fmt.Fprintf(stdout, "  %s\n", issue)
+		}
+	}
+	if len(results) > 0 {
+		return fmt.Errorf("lint: %d template(s) with issues", len(results))
+	}
+	fmt.Fprintln(stdout, "all templates ok")
+	return nil
+}
+
+// varFlags collects variables from --vars-file and repeated --var flags.
+type varFlags struct {
+	file string
+	vars multiFlag
+}
+
+// load layers the vars file and then the --var flags over base.
+func (v *varFlags) load(base map[string]string) (map[string]string, error) {
+	var fromFile map[string]string
+	if v.file != "" {
+		var err error
+		if fromFile, err = render.LoadVars(v.file); err != nil {
+			return nil, err
+		}
+	}
+	flags := map[string]string{}
+	for _, kv := range v.vars {
+		k, val, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("--var %q: want KEY=VALUE", kv)
+		}
+		flags[k] = val
+	}
+	return render.MergeVars(base, fromFile, flags), nil
+}
+
+// checkMissing reports every required variable the templates lack in one
+// message.
+func checkMissing(templates []string, vars map[string]string) error {
+	var lines []string
+	for _, name := range templates {
+		missing, err := render.MissingVars(name, vars)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			lines = append(lines, fmt.Sprintf("  %s: %s", name, strings.Join(missing, ", ")))
+		}
+	}
+	if len(lines) > 0 {
+		return fmt.Errorf("missing required variables (set them with --var KEY=VALUE or --vars-file):\n%s", strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+// warnUnknown prints an unknown-variables warning and drops it; any other
+// error is returned.
+func warnUnknown(err error, stderr io.Writer) error {
+	if err != nil && errors.Is(err, render.ErrUnknownVars) && !errors.Is(err, render.ErrUnsubstituted) {
+		fmt.Fprintln(stderr, "hhclub: warning:", err)
+		return nil
+	}
+	return err
+}
+
+type multiFlag []string
+
+func (m *multiFlag) String() string { return strings.Join(*m, ",") }
+
+func (m *multiFlag) Set(s string) error {
+	*m = append(*m, s)
+	return nil
+}
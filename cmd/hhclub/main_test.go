@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderFlagsAroundName(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args func(out string) []string
+	}{
+		{"name first", func(out string) []string {
+			return []string{"render", "make/Makefile", "--var", "BUILD_CMD=go build ./cmd/api", "-o", out}
+		}},
+		{"name between flags", func(out string) []string {
+			return []string{"render", "--var", "BUILD_CMD=go build ./cmd/api", "make/Makefile", "-o", out}
+		}},
+		{"name last", func(out string) []string {
+			return []string{"render", "--var", "BUILD_CMD=go build ./cmd/api", "-o", out, "make/Makefile"}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "Makefile")
+			var stdout, stderr bytes.Buffer
+			if err := run(tc.args(out), &stdout, &stderr); err != nil {
+				t.Fatalf("run: %v (stderr: %s)", err, stderr.String())
+			}
+			if stdout.Len() > 0 {
+				t.Errorf("rendered to stdout despite -o:\n%s", stdout.String())
+			}
+			b, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("-o file not written: %v", err)
+			}
+			if !strings.Contains(string(b), "go build ./cmd/api") {
+				t.Errorf("--var not applied:\n%s", b)
+			}
+		})
+	}
+}
+
+func TestRenderRejectsExtraArgs(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"render", "make/Makefile", "git/gitignore"}, &stdout, &stderr)
+	if !errors.Is(err, errUsage) {
+		t.Errorf("run with two template names = %v, want errUsage", err)
+	}
+}
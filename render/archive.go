@@ -0,0 +1,73 @@
+package render
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RenderArchive renders templateNames like RenderToMap and writes the files
+// to w as a "tar" or "zip" archive, each at the path RenderAll would use and
+// with the same mode, so script templates stay executable. Nothing is
+// written if rendering fails; an ErrUnknownVars warning is returned after
+// the archive is complete.
+func RenderArchive(w io.Writer, format string, templateNames []string, vars map[string]string) error {
+	var write func(io.Writer, []renderedFile) error
+	switch format {
+	case "tar":
+		write = writeTar
+	case "zip":
+		write = writeZip
+	default:
+		return fmt.Errorf("render: archive: unknown format %q, want tar or zip", format)
+	}
+	files, err := renderSet(templateNames, projectFiles, vars)
+	if files == nil {
+		return err
+	}
+	if werr := write(w, files); werr != nil {
+		return fmt.Errorf("render: archive: %w", werr)
+	}
+	return err
+}
+
+// archiveTime stamps every entry so the same input gives the same archive.
+var archiveTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func writeTar(w io.Writer, files []renderedFile) error {
+	tw := tar.NewWriter(w)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:    f.path,
+			Mode:    int64(f.mode),
+			Size:    int64(len(f.content)),
+			ModTime: archiveTime,
+			Format:  tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(tw, f.content); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeZip(w io.Writer, files []renderedFile) error {
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		hdr := &zip.FileHeader{Name: f.path, Method: zip.Deflate, Modified: archiveTime}
+		hdr.SetMode(f.mode)
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderArchive(t *testing.T) {
+	names := []string{"docker/Dockerfile", "github/workflow"}
+	vars := map[string]string{
+		"IMAGE_NAME": "app",
+		"BUILD_CMD":  "go build ./...",
+		"TEST_CMD":   "go test ./...",
+		"SONAR_HOST": "sonar.example.com",
+	}
+	want, err := RenderToMap(names, vars)
+	if err != nil {
+		t.Fatalf("RenderToMap: %v", err)
+	}
+
+	for _, format := range []string{"tar", "zip"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderArchive(&buf, format, names, vars); err != nil {
+				t.Fatalf("RenderArchive: %v", err)
+			}
+			got, modes := readArchive(t, format, buf.Bytes())
+			if !reflect.DeepEqual(got, want) {
+				var paths []string
+				for p := range got {
+					paths = append(paths, p)
+				}
+				t.Errorf("archive holds %v, want the RenderToMap files", paths)
+			}
+			for p, mode := range modes {
+				if mode != 0o644 {
+					t.Errorf("%s mode = %v, want 0644", p, mode)
+				}
+			}
+			for _, p := range []string{"Dockerfile", ".dockerignore", ".github/workflows/ci.yml", ".gitignore"} {
+				if _, ok := got[p]; !ok {
+					t.Errorf("archive lacks %s", p)
+				}
+			}
+
+			var again bytes.Buffer
+			if err := RenderArchive(&again, format, names, vars); err != nil || !bytes.Equal(again.Bytes(), buf.Bytes()) {
+				t.Errorf("second archive differs (err %v)", err)
+			}
+		})
+	}
+}
+
+func TestRenderArchiveErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderArchive(&buf, "rar", []string{"make/Makefile"}, nil); err == nil || !strings.Contains(err.Error(), `unknown format "rar"`) {
+		t.Errorf("RenderArchive(rar) error = %v, want an unknown format error", err)
+	}
+	var missing *MissingVarsError
+	if err := RenderArchive(&buf, "tar", []string{"github/workflow"}, nil); !errors.As(err, &missing) {
+		t.Errorf("RenderArchive without IMAGE_NAME error = %v, want a *MissingVarsError", err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("failed renders wrote %d bytes", buf.Len())
+	}
+	err := RenderArchive(&buf, "zip", []string{"make/Makefile"}, map[string]string{"BUILD_CMD": "go build", "TYPO": "x"})
+	if !errors.Is(err, ErrUnknownVars) || buf.Len() == 0 {
+		t.Errorf("RenderArchive with an unknown key = %v after %d bytes, want a complete archive and ErrUnknownVars", err, buf.Len())
+	}
+}
+
+// readArchive returns the contents and modes of a tar or zip archive's files.
+func readArchive(t *testing.T, format string, b []byte) (map[string]string, map[string]fs.FileMode) {
+	t.Helper()
+	files, modes := map[string]string{}, map[string]fs.FileMode{}
+	switch format {
+	case "tar":
+		tr := tar.NewReader(bytes.NewReader(b))
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("read tar: %v", err)
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatalf("read tar %s: %v", hdr.Name, err)
+			}
+			files[hdr.Name], modes[hdr.Name] = string(data), hdr.FileInfo().Mode().Perm()
+		}
+	case "zip":
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			t.Fatalf("read zip: %v", err)
+		}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatalf("open zip %s: %v", f.Name, err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatalf("read zip %s: %v", f.Name, err)
+			}
+			files[f.Name], modes[f.Name] = string(data), f.Mode().Perm()
+		}
+	}
+	return files, modes
+}
@@ -0,0 +1,92 @@
+package render
+
+import "sort"
+
+// KnownVars is the canonical set of variable names the shipped templates
+// use. A token outside this set that only one template mentions is most
+// likely a typo; see AuditTemplates.
+var KnownVars = map[string]bool{
+	"ALPINE_IMAGE_DIGEST":    true,
+	"ALPINE_VERSION":         true,
+	"APP_GID":                true,
+	"APP_NAME":               true,
+	"APP_UID":                true,
+	"APP_VERSION":            true,
+	"BUILD_ARGS":             true,
+	"BUILD_CMD":              true,
+	"CHART_VERSION":          true,
+	"COVERAGE":               true,
+	"CPU_LIMIT":              true,
+	"CPU_REQUEST":            true,
+	"ENABLE_CACHE":           true,
+	"ENABLE_PROTO":           true,
+	"EXCLUDE_TESTS":          true,
+	"GO_IMAGE_DIGEST":        true,
+	"GO_VERSION":             true,
+	"HEALTHCHECK_CMD":        true,
+	"HEALTHCHECK_PORT":       true,
+	"IGNORE_DOCS":            true,
+	"IGNORE_VENDOR":          true,
+	"IMAGE_NAME":             true,
+	"IMAGE_TAG":              true,
+	"LINT_CMD":               true,
+	"LINT_ENFORCE":           true,
+	"MEMORY_LIMIT":           true,
+	"MEMORY_REQUEST":         true,
+	"PLATFORMS":              true,
+	"PORT":                   true,
+	"PROTO_CMD":              true,
+	"REGISTRY":               true,
+	"REGISTRY_CREDENTIAL_ID": true,
+	"REPLICAS":               true,
+	"SONAR_CREDENTIAL_ID":    true,
+	"SONAR_ENFORCE_GATE":     true,
+	"SONAR_HOST":             true,
+	"SONAR_PROJECT_KEY":      true,
+	"TEST_CMD":               true,
+	"TEST_SHARDED":           true,
+	"TEST_SHARDS":            true,
+	"TEST_SHARD_CMD":         true,
+	"TEST_SHARD_INDEXES":     true,
+}
+
+// AuditTemplates scans every registered template for placeholder and
+// condition keys and reports, per template, the keys that appear in no other
+// template and are not in KnownVars, such as {{BULID_CMD}}. An empty result
+// means no suspects.
+func AuditTemplates() map[string][]string {
+	keys := map[string][]string{}
+	for _, e := range registry {
+		_, d, src, err := std.load(e.name, RenderOptions{})
+		if err != nil {
+			continue
+		}
+		for k := range knownKeys(src, d) {
+			keys[e.name] = append(keys[e.name], k)
+		}
+	}
+	return audit(keys)
+}
+
+// audit flags the keys of each template that no other template shares and
+// KnownVars does not list.
+func audit(keys map[string][]string) map[string][]string {
+	uses := map[string]int{}
+	for _, ks := range keys {
+		for _, k := range ks {
+			uses[k]++
+		}
+	}
+	suspects := map[string][]string{}
+	for name, ks := range keys {
+		for _, k := range ks {
+			if uses[k] == 1 && !KnownVars[k] {
+				suspects[name] = append(suspects[name], k)
+			}
+		}
+		if s := suspects[name]; len(s) > 0 {
+			sort.Strings(s)
+		}
+	}
+	return suspects
+}
@@ -0,0 +1,24 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuditTemplates(t *testing.T) {
+	if suspects := AuditTemplates(); len(suspects) > 0 {
+		t.Errorf("AuditTemplates() found unknown placeholders: %v", suspects)
+	}
+}
+
+func TestAuditFlagsTypos(t *testing.T) {
+	got := audit(map[string][]string{
+		"jenkins/Jenkinsfile": {"BUILD_CMD", "BULID_CMD"},
+		"github/workflow":     {"BUILD_CMD", "CUSTOM_FLAG"},
+		"gitlab/gitlab-ci":    {"CUSTOM_FLAG"},
+	})
+	want := map[string][]string{"jenkins/Jenkinsfile": {"BULID_CMD"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("audit() = %v, want %v", got, want)
+	}
+}
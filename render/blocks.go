@@ -0,0 +1,187 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// blockTag matches a block tag such as {{#if KEY}} or {{/if}} and captures
+// the open/close marker, the block kind and the key.
+func (d delims) blockTag() *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(d.left) + `\s*([#/])(\w+)(?:\s+([A-Z][A-Z0-9_]*))?\s*` + regexp.QuoteMeta(d.right))
+}
+
+// conditions returns the keys tested by {{#if KEY}} and {{#unless KEY}}
+// blocks and iterated by {{#each KEY}} blocks in src.
+func conditions(src string, d delims) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, m := range d.blockTag().FindAllStringSubmatch(src, -1) {
+		if m[1] == "#" && m[3] != "" && !seen[m[3]] {
+			seen[m[3]] = true
+			keys = append(keys, m[3])
+		}
+	}
+	return keys
+}
+
+// expandBlocks evaluates the block tags in src:
+//
+//   - {{#if KEY}} ... {{/if}} keeps its body only when value(KEY) is truthy.
+//   - {{#unless KEY}} ... {{/unless}} keeps its body only when it is not.
+//   - {{#each KEY}} ... {{/each}} repeats its body once per item of the
+//     comma-separated list value(KEY), with {{.}} standing for the item and
+//     {{.|FILTER}} for the item passed through one of filters, e.g.
+//     {{.|lower}}.
+//
+// Blocks nest. A tag that sits alone on its line takes the whole line with
+// it, so dropping a block leaves no blank lines behind.
+func expandBlocks(src string, d delims, value func(string) string) (string, error) {
+	type frame struct {
+		kind  string
+		key   string
+		keep  bool // whether the block's body is written
+		start int  // offset in out where the body begins
+	}
+	var (
+		out   bytes.Buffer
+		stack []frame
+		pos   int
+	)
+	keep := func() bool {
+		for _, f := range stack {
+			if !f.keep {
+				return false
+			}
+		}
+		return true
+	}
+	for _, m := range d.blockTag().FindAllStringSubmatchIndex(src, -1) {
+		start, end := standalone(src, m[0], m[1])
+		marker, kind := src[m[2]:m[3]], src[m[4]:m[5]]
+		if kind != "if" && kind != "unless" && kind != "each" {
+			return "", fmt.Errorf("line %d: unknown block %q", lineOf(src, m[0]), kind)
+		}
+		if keep() {
+			out.WriteString(src[pos:start])
+		}
+		pos = end
+		switch marker {
+		case "#":
+			if m[6] < 0 {
+				return "", fmt.Errorf("line %d: %s#%s%s needs a variable", lineOf(src, m[0]), d.left, kind, d.right)
+			}
+			key := src[m[6]:m[7]]
+			f := frame{kind: kind, key: key, keep: true, start: out.Len()}
+			switch kind {
+			case "if":
+				f.keep = truthy(value(key))
+			case "unless":
+				f.keep = !truthy(value(key))
+			}
+			stack = append(stack, f)
+		case "/":
+			if len(stack) == 0 || stack[len(stack)-1].kind != kind {
+				return "", fmt.Errorf("line %d: %s/%s%s without matching #%s", lineOf(src, m[0]), d.left, kind, d.right, kind)
+			}
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if f.kind == "each" {
+				body, err := eachBody(out.Bytes()[f.start:], d, listItems(value(f.key)))
+				if err != nil {
+					return "", fmt.Errorf("line %d: %w", lineOf(src, m[0]), err)
+				}
+				out.Truncate(f.start)
+				out.WriteString(body)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		f := stack[len(stack)-1]
+		return "", fmt.Errorf("unclosed %s#%s %s%s block", d.left, f.kind, f.key, d.right)
+	}
+	if keep() {
+		out.WriteString(src[pos:])
+	}
+	return out.String(), nil
+}
+
+// itemTag matches {{.}} and {{.|FILTER}} inside an #each block and captures
+// the filter name.
+func (d delims) itemTag() *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(d.left) + `\s*\.\s*(?:\|\s*(\w+)\s*)?` + regexp.QuoteMeta(d.right))
+}
+
+// eachBody writes body once per item with its item tags filled in.
+func eachBody(body []byte, d delims, items []string) (string, error) {
+	tag := d.itemTag()
+	var out strings.Builder
+	for _, item := range items {
+		var err error
+		out.WriteString(tag.ReplaceAllStringFunc(string(body), func(m string) string {
+			name := tag.FindStringSubmatch(m)[1]
+			if name == "" {
+				return item
+			}
+			f, ok := filters[name]
+			if !ok {
+				err = fmt.Errorf("unknown filter %q", name)
+				return m
+			}
+			return f(item)
+		}))
+		if err != nil {
+			return "", err
+		}
+	}
+	return out.String(), nil
+}
+
+// listItems splits a comma-separated list value, dropping blank items.
+func listItems(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// truthy reports whether a toggle value switches a block on: anything but
+// the empty string, values strconv.ParseBool reads as false ("false", "0",
+// "f", ...) and YAML 1.1's "no" and "off" in any case, which LoadVars keeps
+// as written.
+func truthy(v string) bool {
+	if v == "" || strings.EqualFold(v, "no") || strings.EqualFold(v, "off") {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	return err != nil || b
+}
+
+// standalone widens [start, end) to the whole line, including its newline,
+// when the tag is the only thing on it.
+func standalone(src string, start, end int) (int, int) {
+	ls := strings.LastIndexByte(src[:start], '\n') + 1
+	if strings.TrimSpace(src[ls:start]) != "" {
+		return start, end
+	}
+	le := strings.IndexByte(src[end:], '\n')
+	if le < 0 {
+		le = len(src)
+	} else {
+		le += end + 1
+	}
+	if strings.TrimSpace(src[end:le]) != "" {
+		return start, end
+	}
+	return ls, le
+}
+
+func lineOf(src string, off int) int {
+	return strings.Count(src[:off], "\n") + 1
+}
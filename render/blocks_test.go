@@ -0,0 +1,74 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandBlocks(t *testing.T) {
+	d := delims{defaultLeftDelim, defaultRightDelim}
+	vars := map[string]string{"ON": "true", "OFF": "false", "NO": "no", "OFF_UPPER": "Off", "ARGS": "Version, Commit"}
+	value := func(k string) string { return vars[k] }
+
+	for _, tc := range []struct {
+		name, src, want string
+	}{
+		{
+			name: "nested if and unless",
+			src:  "a\n{{#if ON}}\nb\n{{#unless ON}}\nc\n{{/unless}}\n{{#unless OFF}}\nd\n{{/unless}}\n{{/if}}\n{{#if OFF}}\n{{#unless OFF}}\ne\n{{/unless}}\n{{/if}}\nf\n",
+			want: "a\nb\nd\nf\n",
+		},
+		{
+			name: "standalone tags leave no blank lines",
+			src:  "a\n  {{#if OFF}}  \nb\n  {{/if}}\nc\n",
+			want: "a\nc\n",
+		},
+		{
+			name: "inline tags keep the line",
+			src:  "x{{#if ON}}-on{{/if}}{{#if OFF}}-off{{/if}}\n",
+			want: "x-on\n",
+		},
+		{
+			name: "no and off are false",
+			src:  "{{#if NO}}\na\n{{/if}}\n{{#if OFF_UPPER}}\nb\n{{/if}}\n{{#unless NO}}\nc\n{{/unless}}\n{{#if ARGS}}\nd\n{{/if}}\n",
+			want: "c\nd\n",
+		},
+		{
+			name: "each with item filters",
+			src:  "{{#each ARGS}}\nARG {{.}}\nX {{.|lower}}={{.|upper}}\n{{/each}}\n",
+			want: "ARG Version\nX version=VERSION\nARG Commit\nX commit=COMMIT\n",
+		},
+		{
+			name: "each over an unset list",
+			src:  "a\n{{#each NONE}}\n{{.}}\n{{/each}}\nb\n",
+			want: "a\nb\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandBlocks(tc.src, d, value)
+			if err != nil {
+				t.Fatalf("expandBlocks: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expandBlocks() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name, src, wantErr string
+	}{
+		{"close of another kind", "{{#if ON}}\na\n{{/unless}}\n", "line 3: {{/unless}} without matching #unless"},
+		{"close without open", "a\n{{/if}}\n", "line 2: {{/if}} without matching #if"},
+		{"unclosed block", "{{#if ON}}\n{{#each ARGS}}\na\n{{/each}}\n", "unclosed {{#if ON}} block"},
+		{"unknown block", "{{#with ON}}\n{{/with}}\n", `unknown block "with"`},
+		{"unknown item filter", "{{#each ARGS}}{{.|title}}{{/each}}\n", `unknown filter "title"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := expandBlocks(tc.src, d, value)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expandBlocks() error = %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
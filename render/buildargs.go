@@ -0,0 +1,23 @@
+package render
+
+import "strings"
+
+// WithBuildArgs returns a copy of vars with BUILD_ARGS set to args. The
+// Dockerfile templates declare an ARG for each name in the builder stage and
+// stamp it into the binary with -ldflags "-X main.<name>=$<NAME>", the name
+// lowercased, so
+//
+//	WithBuildArgs(vars, []string{"VERSION", "COMMIT"})
+//
+// pairs with docker build --build-arg VERSION=1.2.3 --build-arg COMMIT=abc
+// and package-level version and commit strings in main. Blank names are
+// dropped.
+func WithBuildArgs(vars map[string]string, args []string) map[string]string {
+	names := make([]string, 0, len(args))
+	for _, a := range args {
+		if a = strings.TrimSpace(a); a != "" {
+			names = append(names, a)
+		}
+	}
+	return MergeVars(vars, map[string]string{"BUILD_ARGS": strings.Join(names, ",")})
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	hhclub "github.com/loufp/hhClub"
+)
+
+// ServiceSpec describes one service in a generated docker-compose file.
+type ServiceSpec struct {
+	Name  string
+	Image string // empty builds the service from the Dockerfile in the project root
+	Ports []string
+	Env   map[string]string
+	// DependsOn names other services in the same file.
+	DependsOn []string
+}
+
+// composeFile loops over services, so it is a text/template rather than a
+// placeholder template. The .gotmpl extension keeps it out of the registry
+// and out of Renderer.List.
+const composeFile = "compose/docker-compose.yml.gotmpl"
+
+// RenderCompose renders a docker-compose file with one block per service.
+// A service without an Image is built from the project's Dockerfile and
+// tagged ${IMAGE_NAME}, the same image name the CI templates push, falling
+// back to the service name when IMAGE_NAME is not set in the environment.
+// Services without ports get no ports key.
+func RenderCompose(services []ServiceSpec) (string, error) {
+	if len(services) == 0 {
+		return "", fmt.Errorf("render: compose: no services")
+	}
+	names := map[string]bool{}
+	for _, s := range services {
+		if s.Name == "" {
+			return "", fmt.Errorf("render: compose: service with empty name")
+		}
+		if names[s.Name] {
+			return "", fmt.Errorf("render: compose: duplicate service %q", s.Name)
+		}
+		names[s.Name] = true
+	}
+	for _, s := range services {
+		for _, dep := range s.DependsOn {
+			if !names[dep] {
+				return "", fmt.Errorf("render: compose: service %q depends on unknown service %q", s.Name, dep)
+			}
+		}
+	}
+
+	src, err := hhclub.Template(composeFile)
+	if err != nil {
+		return "", fmt.Errorf("render: load compose: %w", err)
+	}
+	tmpl, err := template.New(composeFile).Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(string(src))
+	if err != nil {
+		return "", fmt.Errorf("render: parse compose: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, services); err != nil {
+		return "", fmt.Errorf("render: compose: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderCompose(t *testing.T) {
+	out, err := RenderCompose([]ServiceSpec{
+		{Name: "api", Ports: []string{"8080:8080"}, Env: map[string]string{"DB_URL": "postgres://db:5432/app?x=a: b"}, DependsOn: []string{"db"}},
+		{Name: "db", Image: "postgres:16"},
+	})
+	if err != nil {
+		t.Fatalf("RenderCompose: %v", err)
+	}
+	var doc struct {
+		Services map[string]map[string]any `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not YAML: %v\n%s", err, out)
+	}
+	api, db := doc.Services["api"], doc.Services["db"]
+	if api["build"] != "." || api["image"] != "${IMAGE_NAME:-api}" {
+		t.Errorf("api is not built from the Dockerfile:\n%s", out)
+	}
+	if env, _ := api["environment"].(map[string]any); env["DB_URL"] != "postgres://db:5432/app?x=a: b" {
+		t.Errorf("api environment = %v", api["environment"])
+	}
+	if ports, _ := api["ports"].([]any); len(ports) != 1 || ports[0] != "8080:8080" {
+		t.Errorf("api ports = %v", api["ports"])
+	}
+	if deps, _ := api["depends_on"].([]any); len(deps) != 1 || deps[0] != "db" {
+		t.Errorf("api depends_on = %v", api["depends_on"])
+	}
+	if db["image"] != "postgres:16" {
+		t.Errorf("db image = %v", db["image"])
+	}
+	for _, key := range []string{"ports", "build", "environment", "depends_on"} {
+		if _, ok := db[key]; ok {
+			t.Errorf("db has a %s key:\n%s", key, out)
+		}
+	}
+}
+
+func TestRenderComposeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		services []ServiceSpec
+		wantErr  string
+	}{
+		{"no services", nil, "no services"},
+		{"empty name", []ServiceSpec{{Image: "x"}}, "empty name"},
+		{"duplicate name", []ServiceSpec{{Name: "api"}, {Name: "api", Image: "x"}}, `duplicate service "api"`},
+		{"unknown depends_on", []ServiceSpec{{Name: "api", DependsOn: []string{"cache"}}}, `service "api" depends on unknown service "cache"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := RenderCompose(tc.services)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("RenderCompose() = %q, %v; want an error containing %q", out, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package render
+
+import "strings"
+
+// coverageProfile is the file the CI templates archive when COVERAGE is on.
+const coverageProfile = "coverage.out"
+
+// WithCoverage returns a copy of vars with COVERAGE switched on and TEST_CMD
+// writing a coverage profile to coverage.out, which the CI templates then
+// archive. A TEST_CMD that runs go test gets -coverprofile added after
+// "go test"; one that already writes a profile or is not go test is kept as
+// is. Without a TEST_CMD the default go test ./... is used.
+//
+// Setting COVERAGE alone only adds the archive steps; WithCoverage keeps the
+// two in step.
+func WithCoverage(vars map[string]string) map[string]string {
+	out := MergeVars(vars, map[string]string{"COVERAGE": "true"})
+	cmd, ok := out["TEST_CMD"]
+	if !ok || cmd == "" {
+		cmd = "go test ./..."
+	}
+	if rest, ok := strings.CutPrefix(cmd, "go test"); ok && !strings.Contains(rest, "-coverprofile") && (rest == "" || rest[0] == ' ') {
+		cmd = "go test -coverprofile=" + coverageProfile + rest
+	}
+	out["TEST_CMD"] = cmd
+	return out
+}
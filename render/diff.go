@@ -0,0 +1,171 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// DiffTemplate renders the named template and returns a unified diff from the
+// current contents of outPath to the rendered output, or "" when they are
+// identical. A missing outPath is diffed as empty, so every line shows as
+// added. Nothing is written; this backs a dry-run mode for WriteTemplate.
+func DiffTemplate(name, outPath string, vars map[string]string) (string, error) {
+	return std.Diff(name, outPath, vars)
+}
+
+// Diff is DiffTemplate for r's templates.
+func (r *Renderer) Diff(name, outPath string, vars map[string]string) (string, error) {
+	out, err := r.Render(name, vars)
+	if !rendered(err) {
+		return "", err
+	}
+	from := outPath
+	cur, rerr := os.ReadFile(outPath)
+	if errors.Is(rerr, fs.ErrNotExist) {
+		from = "/dev/null"
+	} else if rerr != nil {
+		return "", fmt.Errorf("render: diff %s: %w", outPath, rerr)
+	}
+	return unifiedDiff(from, outPath, string(cur), out), err
+}
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedDiff compares a and b line by line and formats the result with
+// diffContext lines of context around each change.
+func unifiedDiff(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk while the next change is close enough that the
+		// context around both would touch.
+		last := i
+		for j := i + 1; j < len(ops) && j-last <= 2*diffContext+1; j++ {
+			if ops[j].kind != ' ' {
+				last = j
+			}
+		}
+		start := max(0, i-diffContext)
+		end := min(len(ops), last+diffContext+1)
+		writeHunk(&sb, ops, start, end)
+		i = end
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []diffOp, start, end int) {
+	oldLine, newLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			oldLine++
+		}
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+	oldLen, newLen := 0, 0
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			oldLen++
+		}
+		if op.kind != '-' {
+			newLen++
+		}
+	}
+	if oldLen == 0 {
+		oldLine--
+	}
+	if newLen == 0 {
+		newLine--
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldLine, oldLen), hunkRange(newLine, newLen))
+	for _, op := range ops[start:end] {
+		sb.WriteByte(op.kind)
+		if strings.HasSuffix(op.line, "\n") {
+			sb.WriteString(op.line)
+		} else {
+			sb.WriteString(op.line + "\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits s after each newline, keeping the terminators so a
+// missing final newline still counts as a difference.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes an edit script from a to b using the longest common
+// subsequence of lines. Templates are small, so the quadratic table is fine.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// hunkRange formats one side of a hunk header as diff -u does, leaving out a
+// count of 1.
+func hunkRange(line, n int) string {
+	if n == 1 {
+		return fmt.Sprint(line)
+	}
+	return fmt.Sprintf("%d,%d", line, n)
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The expected output in these tests is what diff -u prints for the same
+// inputs.
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(ls ...string) string { return strings.Join(ls, "\n") + "\n" }
+	numbers := func(n int, edit map[int]string) string {
+		var ls []string
+		for i := 1; i <= n; i++ {
+			if s, ok := edit[i]; ok {
+				ls = append(ls, s)
+			} else {
+				ls = append(ls, fmt.Sprint(i))
+			}
+		}
+		return lines(ls...)
+	}
+	for _, tc := range []struct {
+		name, from, a, b, want string
+	}{
+		{
+			name: "changes six lines apart share a hunk",
+			from: "old",
+			a:    lines("a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"),
+			b:    lines("A", "b", "c", "d", "e", "f", "g", "H", "i", "j", "k", "l"),
+			want: "--- old\n+++ new\n@@ -1,11 +1,11 @@\n-a\n+A\n b\n c\n d\n e\n f\n g\n-h\n+H\n i\n j\n k\n",
+		},
+		{
+			name: "distant changes get their own hunks",
+			from: "old",
+			a:    numbers(16, nil),
+			b:    numbers(16, map[int]string{1: "one", 16: "sixteen"}),
+			want: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -13,4 +13,4 @@\n 13\n 14\n 15\n-16\n+sixteen\n",
+		},
+		{
+			name: "from an empty file",
+			from: "/dev/null",
+			b:    lines("x", "y"),
+			want: "--- /dev/null\n+++ new\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "one line grown to fifteen",
+			from: "old",
+			a:    lines("x"),
+			b:    lines("x", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14"),
+			want: "--- old\n+++ new\n@@ -1 +1,15 @@\n x\n+1\n+2\n+3\n+4\n+5\n+6\n+7\n+8\n+9\n+10\n+11\n+12\n+13\n+14\n",
+		},
+		{
+			name: "one line replaced",
+			from: "old",
+			a:    lines("a"),
+			b:    lines("b"),
+			want: "--- old\n+++ new\n@@ -1 +1 @@\n-a\n+b\n",
+		},
+		{
+			name: "identical",
+			from: "old",
+			a:    lines("x"),
+			b:    lines("x"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := unifiedDiff(tc.from, "new", tc.a, tc.b); got != tc.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDiffTemplateMissingFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), ".dockerignore")
+	rendered, err := RenderTemplate("docker/dockerignore", nil)
+	if err != nil {
+		t.Fatalf("RenderTemplate: %v", err)
+	}
+	got, err := DiffTemplate("docker/dockerignore", out, nil)
+	if err != nil {
+		t.Fatalf("DiffTemplate: %v", err)
+	}
+	n := strings.Count(rendered, "\n")
+	want := fmt.Sprintf("--- /dev/null\n+++ %s\n@@ -0,0 +1,%d @@\n", out, n)
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("DiffTemplate() header =\n%s\nwant\n%s", got, want)
+	}
+	if body := strings.TrimPrefix(got, want); body != "+"+strings.ReplaceAll(strings.TrimSuffix(rendered, "\n"), "\n", "\n+")+"\n" {
+		t.Errorf("DiffTemplate() body =\n%s", body)
+	}
+}
@@ -0,0 +1,160 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// instruction is one logical Dockerfile instruction with continuation
+// lines folded in.
+type instruction struct {
+	line int // 1-based line the instruction starts on
+	cmd  string
+	args string
+}
+
+func parseDockerfile(content string) []instruction {
+	var (
+		out  []instruction
+		cur  *instruction
+		body strings.Builder
+	)
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if cur == nil {
+			cmd, args, _ := strings.Cut(line, " ")
+			cur = &instruction{line: i + 1, cmd: strings.ToUpper(cmd)}
+			line = strings.TrimSpace(args)
+		}
+		more := strings.HasSuffix(line, "\\")
+		body.WriteString(strings.TrimSuffix(line, "\\"))
+		if more {
+			body.WriteByte(' ')
+			continue
+		}
+		cur.args = strings.TrimSpace(body.String())
+		out = append(out, *cur)
+		cur = nil
+		body.Reset()
+	}
+	if cur != nil {
+		cur.args = strings.TrimSpace(body.String())
+		out = append(out, *cur)
+	}
+	return out
+}
+
+// ValidateDockerfile runs a lightweight lint over rendered Dockerfile
+// content: every instruction needs an argument, FROM must come before any
+// instruction other than ARG, and the final stage must set ENTRYPOINT or CMD.
+// The warnings from CheckDockerCacheOrder are included. It returns nil when
+// no issues are found.
+func ValidateDockerfile(content string) []error {
+	var errs []error
+	seenFrom, hasEntry := false, false
+	for _, in := range parseDockerfile(content) {
+		if in.args == "" {
+			errs = append(errs, fmt.Errorf("line %d: %s has no argument", in.line, in.cmd))
+		}
+		switch in.cmd {
+		case "FROM":
+			seenFrom, hasEntry = true, false
+		case "ARG":
+		default:
+			if !seenFrom {
+				errs = append(errs, fmt.Errorf("line %d: %s before the first FROM", in.line, in.cmd))
+			}
+			if in.cmd == "ENTRYPOINT" || in.cmd == "CMD" {
+				hasEntry = true
+			}
+		}
+	}
+	if !seenFrom {
+		errs = append(errs, fmt.Errorf("no FROM instruction"))
+	} else if !hasEntry {
+		errs = append(errs, fmt.Errorf("final stage has no ENTRYPOINT or CMD"))
+	}
+	for _, w := range CheckDockerCacheOrder(content) {
+		errs = append(errs, errors.New(w))
+	}
+	return errs
+}
+
+// CheckDockerCacheOrder checks that each stage keeps the cache-friendly
+// order the shipped Dockerfile uses: COPY go.mod (and go.sum), then
+// go mod download, then COPY . . for the sources. It warns when the whole
+// build context is copied before go.mod or before the dependency download in
+// the same stage, since every source change then invalidates the module
+// cache layer. COPY --from between stages is ignored. It returns nil when
+// the order is fine.
+func CheckDockerCacheOrder(content string) []string {
+	var (
+		warns        []string
+		contextLine  int // first COPY of the whole build context in this stage
+		seenGoMod    bool
+		seenDownload bool
+	)
+	for _, in := range parseDockerfile(content) {
+		switch in.cmd {
+		case "FROM":
+			contextLine, seenGoMod, seenDownload = 0, false, false
+		case "COPY", "ADD":
+			srcs, ok := copySources(in.args)
+			if !ok {
+				continue
+			}
+			for _, src := range srcs {
+				switch {
+				case src == "." || src == "./":
+					if contextLine == 0 {
+						contextLine = in.line
+					}
+				case path.Base(src) == "go.mod" && !seenGoMod:
+					seenGoMod = true
+					if contextLine != 0 {
+						warns = append(warns, fmt.Sprintf("line %d: go.mod is copied after the build context (line %d); copy it first so dependencies cache separately", in.line, contextLine))
+					}
+				}
+			}
+		case "RUN":
+			if strings.Contains(in.args, "go mod download") && !seenDownload {
+				seenDownload = true
+				if contextLine != 0 {
+					warns = append(warns, fmt.Sprintf("line %d: go mod download runs after the build context is copied (line %d); every source change re-downloads dependencies", in.line, contextLine))
+				}
+			}
+		}
+	}
+	return warns
+}
+
+// copySources returns the source paths of a COPY or ADD instruction, in
+// shell or JSON form. ok is false for copies from another stage or image.
+func copySources(args string) (srcs []string, ok bool) {
+	var fields []string
+	for _, f := range strings.Fields(args) {
+		if strings.HasPrefix(f, "--") {
+			if strings.HasPrefix(f, "--from=") {
+				return nil, false
+			}
+			continue
+		}
+		fields = append(fields, f)
+	}
+	if rest := strings.Join(fields, " "); strings.HasPrefix(rest, "[") {
+		fields = nil
+		if err := json.Unmarshal([]byte(rest), &fields); err != nil {
+			return nil, true
+		}
+	}
+	if len(fields) < 2 {
+		return nil, true
+	}
+	return fields[:len(fields)-1], true
+}
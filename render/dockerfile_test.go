@@ -0,0 +1,97 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckDockerCacheOrder(t *testing.T) {
+	for _, tc := range []struct {
+		name, content string
+		want          []string // substrings of each warning, in order
+	}{
+		{
+			name:    "cache-friendly order",
+			content: "FROM golang AS builder\nCOPY go.mod go.sum ./\nRUN go mod download\nCOPY . .\nRUN go build ./...\n",
+		},
+		{
+			name:    "context before go.mod and download",
+			content: "FROM golang\nCOPY . .\nCOPY go.mod ./\nRUN go mod download\n",
+			want:    []string{"line 3: go.mod is copied after the build context (line 2)", "line 4: go mod download runs after the build context is copied (line 2)"},
+		},
+		{
+			name:    "JSON form",
+			content: "FROM golang\nCOPY [\"./\", \"/src\"]\nCOPY [\"go.mod\", \"go.sum\", \"./\"]\n",
+			want:    []string{"line 3: go.mod is copied after the build context (line 2)"},
+		},
+		{
+			name:    "ADD with flags and a nested go.mod",
+			content: "FROM golang\nADD --chown=app . .\nADD --chown=app api/go.mod api/\n",
+			want:    []string{"line 3: go.mod is copied after the build context (line 2)"},
+		},
+		{
+			name:    "copies from another stage are ignored",
+			content: "FROM golang AS builder\nCOPY go.mod ./\nRUN go mod download\nCOPY . .\nFROM alpine\nCOPY --from=builder . .\nCOPY --from=builder /app/go.mod /go.mod\n",
+		},
+		{
+			name:    "each stage starts afresh",
+			content: "FROM golang AS a\nCOPY . .\nFROM golang AS b\nCOPY go.mod ./\nRUN go mod download\n",
+		},
+		{
+			name:    "continuation lines",
+			content: "FROM golang\nCOPY . \\\n  .\nRUN apk add git && \\\n    go mod download\n",
+			want:    []string{"line 4: go mod download runs after the build context is copied (line 2)"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CheckDockerCacheOrder(tc.content)
+			if len(got) != len(tc.want) {
+				t.Fatalf("CheckDockerCacheOrder() = %q, want %d warnings %q", got, len(tc.want), tc.want)
+			}
+			for i, w := range tc.want {
+				if !strings.Contains(got[i], w) {
+					t.Errorf("warning %d = %q, want one containing %q", i, got[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestCopySources(t *testing.T) {
+	for _, tc := range []struct {
+		args string
+		srcs []string
+		ok   bool
+	}{
+		{"go.mod go.sum ./", []string{"go.mod", "go.sum"}, true},
+		{"--chown=app:app --chmod=644 . /app", []string{"."}, true},
+		{`["go.mod", "go.sum", "./"]`, []string{"go.mod", "go.sum"}, true},
+		{`--link ["a b", "/dst"]`, []string{"a b"}, true},
+		{"--from=builder /app/bin/app /usr/local/bin/app", nil, false},
+		{"onlyone", nil, true},
+		{`["unterminated"`, nil, true},
+	} {
+		srcs, ok := copySources(tc.args)
+		if ok != tc.ok || !reflect.DeepEqual(srcs, tc.srcs) {
+			t.Errorf("copySources(%q) = %q, %v; want %q, %v", tc.args, srcs, ok, tc.srcs, tc.ok)
+		}
+	}
+}
+
+func TestShippedDockerfilesCacheOrder(t *testing.T) {
+	for _, name := range []string{"docker/Dockerfile", "docker/Dockerfile-test"} {
+		for _, vars := range []map[string]string{
+			nil,
+			{"PLATFORMS": "linux/amd64,linux/arm64", "ENABLE_PROTO": "true", "BUILD_ARGS": "Version"},
+		} {
+			out, err := RenderTemplate(name, vars)
+			if err != nil {
+				t.Fatalf("RenderTemplate(%s, %v): %v", name, vars, err)
+			}
+			if w := CheckDockerCacheOrder(out); len(w) > 0 {
+				t.Errorf("%s with %v: %q", name, vars, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingVarsError reports placeholders in a template that had neither a
+// value nor a default. It matches ErrUnsubstituted with errors.Is; use
+// errors.As to get at the keys, e.g. to prompt for them.
+type MissingVarsError struct {
+	Template string
+	Vars     []string
+}
+
+func (e *MissingVarsError) Error() string {
+	return fmt.Sprintf("render: %s: %v (no value or default): %s", e.Template, ErrUnsubstituted, strings.Join(e.Vars, ", "))
+}
+
+func (e *MissingVarsError) Unwrap() error { return ErrUnsubstituted }
+
+// UnknownTemplateError reports a template name that is neither shipped nor
+// found in a Renderer's file system.
+type UnknownTemplateError struct {
+	Name string
+}
+
+func (e *UnknownTemplateError) Error() string {
+	return fmt.Sprintf("render: unknown template %q", e.Name)
+}
+
+// ValidationError reports the issues found when RenderOptions.Validate or
+// Verify checks a rendered template, or VerifyRendered checks its output.
+type ValidationError struct {
+	Template string
+	Issues   []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("render: %s: invalid output: %s", e.Template, strings.Join(e.Issues, "; "))
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// filters are the functions a placeholder can pass its value through, as in
+// {{BUILD_CMD|shell}}, or an #each item tag, as in {{.|lower}}. A
+// placeholder without a filter is substituted as is.
+var filters = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"shell": groovySingleQuoted,
+	"yaml":  yamlScalar,
+}
+
+// groovySingleQuoted escapes v for the inside of a Groovy single-quoted
+// string, such as the command in a Jenkinsfile's sh '...' step, so the shell
+// receives v exactly as written.
+func groovySingleQuoted(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`).Replace(v)
+}
+
+// yamlScalar renders v as a YAML scalar that reads back as the string v: as
+// is when it is already a plain string scalar, double-quoted otherwise.
+// Values such as "a: b", "true", "1.20" or "x #y" are quoted.
+func yamlScalar(v string) string {
+	var doc yaml.Node
+	if v != "" && !strings.ContainsAny(v, "\n\r") && yaml.Unmarshal([]byte(v), &doc) == nil && len(doc.Content) == 1 {
+		n := doc.Content[0]
+		if n.Kind == yaml.ScalarNode && n.Style == 0 && n.Tag == "!!str" && n.Value == v {
+			return v
+		}
+	}
+	return strconv.Quote(v)
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// golangImage matches a FROM image reference for the official Go image and
+// captures the major and minor version from its tag, e.g. golang:1.20-alpine
+// or docker.io/library/golang:1.22.3@sha256:....
+var golangImage = regexp.MustCompile(`^(?:(?:docker\.io/)?library/)?golang:(\d+)\.(\d+)`)
+
+// goDirective matches the major and minor version of a go.mod go directive.
+var goDirective = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+// CheckGoVersionConsistency reports an error when a golang stage in the
+// Dockerfile content is older than the go directive in the go.mod file at
+// goModPath, comparing major.minor only. A go 1.22 module fails to build on
+// golang:1.20, and the error from inside docker build rarely says why.
+// Stages on other images, golang tags without a version and a go.mod
+// without a go directive are not checked.
+func CheckGoVersionConsistency(dockerfile string, goModPath string) error {
+	want, err := goModDirective(goModPath, "go")
+	if err != nil {
+		return fmt.Errorf("render: check go version: %w", err)
+	}
+	m := goDirective.FindStringSubmatch(want)
+	if m == nil {
+		return nil
+	}
+	modMajor, modMinor := atoi(m[1]), atoi(m[2])
+
+	for _, in := range parseDockerfile(dockerfile) {
+		if in.cmd != "FROM" {
+			continue
+		}
+		image := fromImage(in.args)
+		v := golangImage.FindStringSubmatch(image)
+		if v == nil {
+			continue
+		}
+		major, minor := atoi(v[1]), atoi(v[2])
+		if major < modMajor || major == modMajor && minor < modMinor {
+			return fmt.Errorf("render: Dockerfile line %d builds with %s but %s requires go %s; set GO_VERSION to at least %s.%s",
+				in.line, image, goModPath, want, m[1], m[2])
+		}
+	}
+	return nil
+}
+
+// fromImage returns the image reference of a FROM instruction, skipping
+// flags such as --platform.
+func fromImage(args string) string {
+	for _, f := range strings.Fields(args) {
+		if !strings.HasPrefix(f, "--") {
+			return f
+		}
+	}
+	return ""
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
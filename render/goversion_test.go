@@ -0,0 +1,77 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeGoMod writes a go.mod with the given contents to a new directory and
+// returns the directory.
+func writeGoMod(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCheckGoVersionConsistency(t *testing.T) {
+	const mod = "module example.com/app\n\ngo 1.22.3\n"
+	for _, tc := range []struct {
+		name, dockerfile string
+		goMod            string
+		wantErr          string
+	}{
+		{name: "older builder", dockerfile: "FROM golang:1.20-alpine AS builder\n", goMod: mod, wantErr: "line 1 builds with golang:1.20-alpine"},
+		{name: "equal minor", dockerfile: "FROM golang:1.22-alpine AS builder\n", goMod: mod},
+		{name: "newer builder", dockerfile: "FROM golang:1.23\n", goMod: mod},
+		{name: "newer major", dockerfile: "FROM golang:2.0\n", goMod: mod},
+		{name: "platform flag", dockerfile: "FROM --platform=$BUILDPLATFORM golang:1.21-alpine AS builder\n", goMod: mod, wantErr: "golang:1.21-alpine"},
+		{name: "digest", dockerfile: "FROM docker.io/library/golang:1.21.5@sha256:0123abcd AS builder\n", goMod: mod, wantErr: "golang:1.21.5@sha256:0123abcd"},
+		{name: "digest and new enough", dockerfile: "FROM golang:1.22@sha256:0123abcd\n", goMod: mod},
+		{name: "later stage", dockerfile: "FROM alpine\nRUN true\nFROM golang:1.19\n", goMod: mod, wantErr: "line 3"},
+		{name: "other images", dockerfile: "FROM alpine:3.20\nFROM golang:latest\n", goMod: mod},
+		{name: "no go directive", dockerfile: "FROM golang:1.13\n", goMod: "module example.com/app\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeGoMod(t, tc.goMod)
+			err := CheckGoVersionConsistency(tc.dockerfile, filepath.Join(dir, "go.mod"))
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("CheckGoVersionConsistency: %v", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("CheckGoVersionConsistency() error = %v, want one containing %q", err, tc.wantErr)
+			case tc.wantErr != "" && !strings.Contains(err.Error(), "set GO_VERSION to at least 1.22"):
+				t.Errorf("error %q does not suggest a GO_VERSION", err)
+			}
+		})
+	}
+
+	if err := CheckGoVersionConsistency("FROM golang:1.22\n", filepath.Join(t.TempDir(), "go.mod")); err == nil {
+		t.Error("CheckGoVersionConsistency with a missing go.mod succeeded")
+	}
+}
+
+func TestRenderAllChecksGoVersion(t *testing.T) {
+	dir := writeGoMod(t, "module example.com/app\n\ngo 1.22\n")
+	vars := map[string]string{"BUILD_CMD": "go build ./...", "GO_VERSION": "1.20"}
+	written, err := RenderAll(dir, []string{"docker/Dockerfile"}, vars)
+	if err == nil || !strings.Contains(err.Error(), "set GO_VERSION to at least 1.22") {
+		t.Errorf("RenderAll with GO_VERSION 1.20 = %v, want a Go version error", err)
+	}
+	if len(written) > 0 {
+		t.Errorf("RenderAll wrote %v before failing", written)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("%s holds %d files, want only go.mod", dir, len(entries))
+	}
+
+	vars["GO_VERSION"] = "1.22"
+	if _, err := RenderAll(dir, []string{"docker/Dockerfile"}, vars); err != nil {
+		t.Errorf("RenderAll with GO_VERSION 1.22: %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package render
+
+// helmChart lists the templates that make up the generated chart.
+var helmChart = []string{"helm/Chart", "helm/values", "helm/deployment", "helm/service"}
+
+// RenderHelmChart writes a minimal Helm chart into outDir: Chart.yaml,
+// values.yaml and templates/deployment.yaml and service.yaml. APP_NAME,
+// IMAGE_NAME and PORT are required; replica count and resource limits
+// surface in values.yaml with defaults. The chart templates keep Helm's own
+// {{ }} syntax because our placeholders are written [[KEY]] there.
+//
+// It returns the files written and, like RenderAll, writes nothing if a
+// variable is missing or a file already exists.
+func RenderHelmChart(outDir string, vars map[string]string) ([]string, error) {
+	return renderInto(outDir, helmChart, nil, vars)
+}
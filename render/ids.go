@@ -0,0 +1,32 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// idVars are the numeric user and group IDs the runtime image is built with
+// and the Kubernetes manifests run as.
+var idVars = []string{"APP_UID", "APP_GID"}
+
+// checkIDs reports an APP_UID or APP_GID that src refers to but that is not
+// a positive integer. Kubernetes can only verify runAsNonRoot against a
+// numeric ID, and 0 is root.
+func checkIDs(src string, d delims, defaults, vars map[string]string) error {
+	known := knownKeys(src, d)
+	value := lookupVar(defaults, vars)
+	for _, k := range idVars {
+		if !known[k] {
+			continue
+		}
+		raw, ok := value(k)
+		if !ok {
+			continue // reported as missing
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(raw)); err != nil || n < 1 {
+			return fmt.Errorf("%s must be a positive integer, got %q", k, raw)
+		}
+	}
+	return nil
+}
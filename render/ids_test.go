@@ -0,0 +1,47 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAppIDs(t *testing.T) {
+	k8s := map[string]string{"APP_NAME": "app", "IMAGE_NAME": "team/app", "PORT": "8080"}
+	for _, k := range idVars {
+		for _, bad := range []string{"0", "-1", "abc", ""} {
+			for _, name := range []string{"docker/Dockerfile", "k8s/deployment", "helm/deployment"} {
+				_, err := RenderTemplate(name, MergeVars(k8s, map[string]string{k: bad}))
+				if want := k + " must be a positive integer"; err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("%s with %s=%q: error = %v, want one containing %q", name, k, bad, err, want)
+				}
+			}
+		}
+	}
+
+	ids := map[string]string{"APP_UID": "1234", "APP_GID": "5678"}
+	for _, tc := range []struct {
+		name string
+		want []string
+	}{
+		{"docker/Dockerfile", []string{"addgroup -S -g 5678 app && adduser -S -u 1234 -G app app", "USER 1234:5678"}},
+		{"docker/Dockerfile-test", []string{"USER 1234:5678"}},
+		{"k8s/deployment", []string{"runAsUser: 1234", "runAsGroup: 5678"}},
+		{"helm/deployment", []string{"runAsUser: 1234", "runAsGroup: 5678"}},
+	} {
+		out, err := RenderTemplate(tc.name, MergeVars(k8s, ids))
+		if err != nil && !errors.Is(err, ErrUnknownVars) {
+			t.Fatalf("RenderTemplate(%s): %v", tc.name, err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s lacks %q:\n%s", tc.name, want, out)
+			}
+		}
+	}
+
+	// The defaults are valid.
+	if out, err := RenderTemplate("docker/Dockerfile", nil); err != nil || !strings.Contains(out, "USER 10001:10001") {
+		t.Errorf("docker/Dockerfile with default IDs: %v\n%s", err, out)
+	}
+}
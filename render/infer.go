@@ -0,0 +1,94 @@
+package render
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// InferGoVars guesses BUILD_CMD, TEST_CMD and IMAGE_NAME for the Go module in
+// moduleDir. A single package under cmd/ is built directly, anything else
+// falls back to "go build ./...". IMAGE_NAME is the last element of the
+// module path in go.mod and is left out when there is no go.mod.
+//
+// The result is a fresh map; copy explicit user values over it so they win.
+func InferGoVars(moduleDir string) (map[string]string, error) {
+	st, err := os.Stat(moduleDir)
+	if err != nil {
+		return nil, fmt.Errorf("render: infer vars: %w", err)
+	}
+	if !st.IsDir() {
+		return nil, fmt.Errorf("render: infer vars: %s is not a directory", moduleDir)
+	}
+
+	vars := map[string]string{
+		"BUILD_CMD": "go build ./...",
+		"TEST_CMD":  "go test ./...",
+	}
+
+	entries, err := os.ReadDir(filepath.Join(moduleDir, "cmd"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("render: infer vars: %w", err)
+	}
+	var cmds []string
+	for _, e := range entries {
+		if e.IsDir() {
+			cmds = append(cmds, e.Name())
+		}
+	}
+	if len(cmds) == 1 {
+		vars["BUILD_CMD"] = "go build ./cmd/" + cmds[0]
+	}
+
+	mod, err := modulePath(filepath.Join(moduleDir, "go.mod"))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("render: infer vars: %w", err)
+	case mod != "":
+		vars["IMAGE_NAME"] = imageName(mod)
+	}
+	return vars, nil
+}
+
+// modulePath reads the module directive from a go.mod file.
+func modulePath(goMod string) (string, error) {
+	return goModDirective(goMod, "module")
+}
+
+// goModDirective returns the argument of the first top-level directive named
+// name in a go.mod file, or "" when there is none.
+func goModDirective(goMod, name string) (string, error) {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if rest, ok := strings.CutPrefix(line, name); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			rest, _, _ = strings.Cut(rest, "//")
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	return "", sc.Err()
+}
+
+var majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// imageName turns a module path into an image name: the last path element,
+// skipping a major version suffix, lowercased as registries require.
+func imageName(mod string) string {
+	name := path.Base(mod)
+	if majorSuffix.MatchString(name) && path.Dir(mod) != "." {
+		name = path.Base(path.Dir(mod))
+	}
+	return strings.ToLower(name)
+}
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInferGoVars(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		goMod string // "" for no go.mod
+		cmds  []string
+		want  map[string]string
+	}{
+		{
+			name:  "single command",
+			goMod: "module github.com/team/api\n\ngo 1.22\n",
+			cmds:  []string{"api"},
+			want:  map[string]string{"BUILD_CMD": "go build ./cmd/api", "TEST_CMD": "go test ./...", "IMAGE_NAME": "api"},
+		},
+		{
+			name:  "several commands",
+			goMod: "module github.com/team/tools\n",
+			cmds:  []string{"a", "b"},
+			want:  map[string]string{"BUILD_CMD": "go build ./...", "TEST_CMD": "go test ./...", "IMAGE_NAME": "tools"},
+		},
+		{
+			name: "no go.mod",
+			cmds: []string{"api"},
+			want: map[string]string{"BUILD_CMD": "go build ./cmd/api", "TEST_CMD": "go test ./..."},
+		},
+		{
+			name:  "major version suffix",
+			goMod: "module \"github.com/Team/MyService/v3\" // moved\n",
+			want:  map[string]string{"BUILD_CMD": "go build ./...", "TEST_CMD": "go test ./...", "IMAGE_NAME": "myservice"},
+		},
+		{
+			name:  "bare module path",
+			goMod: "module v2\n",
+			want:  map[string]string{"BUILD_CMD": "go build ./...", "TEST_CMD": "go test ./...", "IMAGE_NAME": "v2"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.goMod != "" {
+				if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(tc.goMod), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, c := range tc.cmds {
+				if err := os.MkdirAll(filepath.Join(dir, "cmd", c), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// A file under cmd/ is not a command.
+			if len(tc.cmds) > 0 {
+				if err := os.WriteFile(filepath.Join(dir, "cmd", "doc.go"), []byte("package cmd\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := InferGoVars(dir)
+			if err != nil {
+				t.Fatalf("InferGoVars: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("InferGoVars() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := InferGoVars(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("InferGoVars(missing dir) succeeded")
+	}
+}
@@ -0,0 +1,24 @@
+package render
+
+import "path"
+
+// k8sManifests lists the templates RenderK8s renders.
+var k8sManifests = []string{"k8s/deployment", "k8s/service"}
+
+// RenderK8s renders the Kubernetes Deployment and Service manifests from one
+// set of variables (APP_NAME, IMAGE_NAME, PORT and optionally REPLICAS,
+// which defaults to 1) and returns them keyed by file name, e.g.
+// "deployment.yml". Because vars is shared by both manifests, only keys
+// neither of them uses are returned as an ErrUnknownVars warning, alongside
+// the manifests. Missing variables are reported for both at once.
+func RenderK8s(vars map[string]string) (map[string]string, error) {
+	files, err := renderSet(k8sManifests, nil, vars)
+	if files == nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(files))
+	for _, f := range files {
+		out[path.Base(f.path)] = f.content
+	}
+	return out, err
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// LintResult lists the problems LintAll found in one template.
+type LintResult struct {
+	Template string
+	Issues   []string
+}
+
+// LintAll renders every shipped template and checks the output in its own
+// format: Dockerfiles go through ValidateDockerfile and everything else
+// through VerifyRendered.
+//
+// Each template is rendered twice, once with its defaults and once with
+// every {{#if}}, {{#unless}} and {{#each}} key set, so optional sections are
+// covered too. Required variables get placeholder values. Helm chart
+// templates under templates/ keep Helm's own syntax and are only rendered.
+//
+// Only templates with issues are reported; an empty result means every
+// template passed.
+func LintAll() ([]LintResult, error) {
+	var results []LintResult
+	for _, e := range registry {
+		_, d, src, err := std.load(e.name, RenderOptions{})
+		if err != nil {
+			return nil, err
+		}
+		toggles := map[string]string{}
+		for _, k := range conditions(src, d) {
+			toggles[k] = lintValue(k)
+		}
+
+		var issues []string
+		seen := map[string]bool{}
+		for _, pass := range []struct {
+			label string
+			vars  map[string]string
+		}{
+			{"", nil},
+			{"with optional sections: ", toggles},
+		} {
+			for _, issue := range lintTemplate(e, pass.vars) {
+				if !seen[issue] {
+					seen[issue] = true
+					issues = append(issues, pass.label+issue)
+				}
+			}
+		}
+		if len(issues) > 0 {
+			results = append(results, LintResult{Template: e.name, Issues: issues})
+		}
+	}
+	return results, nil
+}
+
+// lintValue is the placeholder value LintAll gives a variable.
+func lintValue(key string) string {
+	return "lint-" + strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// lintTemplate renders e with vars, filling any still-missing variables with
+// placeholder values, and checks the output.
+func lintTemplate(e entry, vars map[string]string) []string {
+	missing, err := MissingVars(e.name, vars)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	fill := map[string]string{}
+	for _, k := range missing {
+		fill[k] = lintValue(k)
+	}
+	out, err := RenderTemplate(e.name, MergeVars(vars, fill))
+	if !rendered(err) {
+		return []string{err.Error()}
+	}
+
+	if path.Base(e.out) != "Dockerfile" {
+		return verifyOutput(e, out, nil)
+	}
+	var issues []string
+	for _, err := range ValidateDockerfile(out) {
+		issues = append(issues, err.Error())
+	}
+	return issues
+}
+
+// checkBrackets reports unbalanced (), [] and {} in Groovy source, ignoring
+// the contents of string literals and comments.
+func checkBrackets(src string) []string {
+	type open struct {
+		ch   byte
+		line int
+	}
+	var (
+		issues []string
+		stack  []open
+		line   = 1
+	)
+	closer := map[byte]byte{')': '(', ']': '[', '}': '{'}
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+		case c == '\'' || c == '"':
+			// Skip to the closing quote, honouring backslash escapes.
+			for i++; i < len(src) && src[i] != c; i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '\n' {
+					line++
+				}
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, open{c, line})
+		case closer[c] != 0:
+			if len(stack) == 0 || stack[len(stack)-1].ch != closer[c] {
+				issues = append(issues, fmt.Sprintf("line %d: unmatched %q", line, c))
+				continue
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	for _, o := range stack {
+		issues = append(issues, fmt.Sprintf("line %d: unclosed %q", o.line, o.ch))
+	}
+	return issues
+}
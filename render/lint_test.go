@@ -0,0 +1,43 @@
+package render
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLintAll(t *testing.T) {
+	results, err := LintAll()
+	if err != nil {
+		t.Fatalf("LintAll: %v", err)
+	}
+	for _, r := range results {
+		t.Errorf("%s:\n\t%v", r.Template, r.Issues)
+	}
+}
+
+func TestCheckBrackets(t *testing.T) {
+	ok := "pipeline {\n  sh 'echo }'\n  // ) in a comment\n  echo \"${x} \\\" {\"\n}\n"
+	if got := checkBrackets(ok); got != nil {
+		t.Errorf("checkBrackets(balanced) = %q, want nil", got)
+	}
+	got := checkBrackets("a {\n  b(\n}\n")
+	want := []string{"line 3: unmatched '}'", "line 1: unclosed '{'", "line 2: unclosed '('"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkBrackets() = %q, want %q", got, want)
+	}
+}
+
+func TestVerifyNamesBreakingValue(t *testing.T) {
+	r := NewRenderer(fstest.MapFS{"ci/pipeline.yml.tmpl": {Data: []byte("steps:\n  - run: {{BUILD_CMD}}\n")}})
+	_, err := r.RenderWith("ci/pipeline.yml", map[string]string{"BUILD_CMD": "go build: fast"}, RenderOptions{Verify: true})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("RenderWith(Verify) error = %v, want *ValidationError", err)
+	}
+	if !strings.Contains(verr.Error(), "quote the value of BUILD_CMD") {
+		t.Errorf("error %q does not suggest quoting BUILD_CMD", verr)
+	}
+}
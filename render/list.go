@@ -0,0 +1,86 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TemplateInfo describes one shipped template.
+type TemplateInfo struct {
+	// Name is the canonical name passed to RenderTemplate, e.g. "docker/Dockerfile".
+	Name string
+	// Category is the provider or format the template belongs to, e.g. "docker" or "jenkins".
+	Category string
+	// Tokens lists the placeholder keys found in the template body, in order
+	// of first appearance.
+	Tokens []string
+}
+
+// ListTemplates reports every shipped template. The tokens are read
+// from the template bodies, using each template's own delimiters, so they
+// follow the files as they change.
+func ListTemplates() []TemplateInfo {
+	return std.List()
+}
+
+func category(name string) string {
+	cat, _, _ := strings.Cut(name, "/")
+	return cat
+}
+
+// tokens returns the unique placeholder keys in src.
+func tokens(src string, d delims) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, m := range d.pattern().FindAllStringSubmatch(src, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			keys = append(keys, m[1])
+		}
+	}
+	return keys
+}
+
+// RequiredVars returns the placeholder keys the named template needs from
+// the caller: every distinct token in its body except those with a value in
+// DefaultVars, and those inside {{#if}} blocks that are off by default.
+func RequiredVars(name string) ([]string, error) {
+	return std.MissingVars(name, nil)
+}
+
+// RequiredVars is the package-level RequiredVars for r's templates.
+func (r *Renderer) RequiredVars(name string) ([]string, error) {
+	return r.MissingVars(name, nil)
+}
+
+// MissingVars returns the keys from RequiredVars that vars does not supply,
+// so a caller can ask for all of them up front instead of discovering a
+// half-rendered file. Tokens inside {{#if}} blocks count only when the block
+// would be kept.
+func MissingVars(name string, vars map[string]string) ([]string, error) {
+	return std.MissingVars(name, vars)
+}
+
+// MissingVars is the package-level MissingVars for r's templates.
+func (r *Renderer) MissingVars(name string, vars map[string]string) ([]string, error) {
+	e, d, src, err := r.load(name, RenderOptions{})
+	if err != nil {
+		return nil, err
+	}
+	derived, err := derivedVars(e.defaults, vars)
+	if err != nil {
+		return nil, fmt.Errorf("render: %s: %w", name, err)
+	}
+	value := lookupVar(MergeVars(e.defaults, derived), vars)
+	body, err := expandBlocks(src, d, func(key string) string { v, _ := value(key); return v })
+	if err != nil {
+		return nil, fmt.Errorf("render: %s: %w", name, err)
+	}
+	var missing []string
+	for _, key := range tokens(body, d) {
+		if _, ok := value(key); !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,178 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// keepMarker and endMarker delimit a region of a generated file the user
+// wants to keep across regeneration. The comment leader can be "#" or "//"
+// so the markers work in Dockerfiles, YAML and Jenkinsfiles alike.
+var (
+	keepMarker = regexp.MustCompile(`^\s*(?:#|//)\s*hhclub:keep\b`)
+	endMarker  = regexp.MustCompile(`^\s*(?:#|//)\s*hhclub:end\b`)
+)
+
+// Conflict is a kept region MergeGenerated could not place in the new
+// output, because the lines around it in the existing file are gone or
+// appear more than once. The region is left out of the merged result.
+type Conflict struct {
+	// Line is the line of the hhclub:keep marker in the existing file.
+	Line int
+	// Region holds the kept lines, markers included.
+	Region string
+	// Reason says why the region could not be placed.
+	Reason string
+}
+
+// keepRegion is one hhclub:keep ... hhclub:end block of an existing file,
+// as half-open line indexes including both markers.
+type keepRegion struct {
+	start, end int
+}
+
+// MergeGenerated carries the regions of a previously generated file that a
+// user wrapped in
+//
+//	# hhclub:keep
+//	...
+//	# hhclub:end
+//
+// over into freshly rendered output, which replaces everything else. Each
+// region is anchored by the nearest non-blank line above it, outside other
+// regions, and goes right after that line in the new output; when that line
+// is missing or not unique, the nearest line below it is tried and the
+// region goes right before it. Regions that cannot be anchored either way
+// are returned as conflicts for the caller to resolve. The hhclub version
+// marker matches across versions, so a region at the top of the file stays
+// there.
+//
+// An hhclub:keep without a matching hhclub:end, or the reverse, is an error.
+func MergeGenerated(existing, newlyRendered string) (string, []Conflict, error) {
+	old := splitLines(existing)
+	regions, err := keepRegions(old)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(regions) == 0 {
+		return newlyRendered, nil, nil
+	}
+	inRegion := make([]bool, len(old))
+	for _, r := range regions {
+		for i := r.start; i < r.end; i++ {
+			inRegion[i] = true
+		}
+	}
+
+	lines := splitLines(newlyRendered)
+	var (
+		top       []keepRegion
+		after     = map[int][]keepRegion{}
+		before    = map[int][]keepRegion{}
+		conflicts []Conflict
+	)
+	for _, r := range regions {
+		above := anchorLine(old, inRegion, r.start-1, -1)
+		if above < 0 {
+			top = append(top, r)
+			continue
+		}
+		if i, ok := findUnique(lines, old[above]); ok {
+			after[i] = append(after[i], r)
+			continue
+		}
+		below := anchorLine(old, inRegion, r.end, 1)
+		if below >= 0 {
+			if i, ok := findUnique(lines, old[below]); ok {
+				before[i] = append(before[i], r)
+				continue
+			}
+		}
+		conflicts = append(conflicts, Conflict{
+			Line:   r.start + 1,
+			Region: strings.Join(old[r.start:r.end], ""),
+			Reason: fmt.Sprintf("context line %d %q is missing or repeated in the new output", above+1, strings.TrimSpace(old[above])),
+		})
+	}
+
+	var b strings.Builder
+	write := func(line string) {
+		b.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	writeRegions := func(rs []keepRegion) {
+		for _, r := range rs {
+			for _, line := range old[r.start:r.end] {
+				write(line)
+			}
+		}
+	}
+	writeRegions(top)
+	for i, line := range lines {
+		writeRegions(before[i])
+		write(line)
+		writeRegions(after[i])
+	}
+	return b.String(), conflicts, nil
+}
+
+// keepRegions finds the hhclub:keep ... hhclub:end blocks in lines.
+func keepRegions(lines []string) ([]keepRegion, error) {
+	var (
+		regions []keepRegion
+		open    = -1
+	)
+	for i, line := range lines {
+		switch {
+		case keepMarker.MatchString(line):
+			if open >= 0 {
+				return nil, fmt.Errorf("render: merge: line %d: hhclub:keep inside the region opened at line %d", i+1, open+1)
+			}
+			open = i
+		case endMarker.MatchString(line):
+			if open < 0 {
+				return nil, fmt.Errorf("render: merge: line %d: hhclub:end without hhclub:keep", i+1)
+			}
+			regions = append(regions, keepRegion{open, i + 1})
+			open = -1
+		}
+	}
+	if open >= 0 {
+		return nil, fmt.Errorf("render: merge: line %d: hhclub:keep without hhclub:end", open+1)
+	}
+	return regions, nil
+}
+
+// anchorLine walks from line i in direction step to the first non-blank line
+// outside any kept region, returning -1 when it runs off the file.
+func anchorLine(lines []string, inRegion []bool, i, step int) int {
+	for ; i >= 0 && i < len(lines); i += step {
+		if !inRegion[i] && strings.TrimSpace(lines[i]) != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// findUnique returns the index of the only line in lines matching anchor,
+// ignoring surrounding whitespace and the hhclub version number.
+func findUnique(lines []string, anchor string) (int, bool) {
+	want := normalizeLine(anchor)
+	found := -1
+	for i, line := range lines {
+		if normalizeLine(line) == want {
+			if found >= 0 {
+				return 0, false
+			}
+			found = i
+		}
+	}
+	return found, found >= 0
+}
+
+func normalizeLine(line string) string {
+	return generatedMarker.ReplaceAllString(strings.TrimSpace(line), "hhclub:version")
+}
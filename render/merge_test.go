@@ -0,0 +1,85 @@
+package render
+
+import "testing"
+
+func TestMergeGenerated(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		existing, new string
+		want          string
+		conflicts     int
+		wantErr       bool
+	}{
+		{
+			name:     "anchored after the line above",
+			existing: "# hhclub:version 1\nFROM a\n# hhclub:keep\nRUN custom\n# hhclub:end\nRUN x\n",
+			new:      "# hhclub:version 1\nFROM a\nRUN y\nRUN x\n",
+			want:     "# hhclub:version 1\nFROM a\n# hhclub:keep\nRUN custom\n# hhclub:end\nRUN y\nRUN x\n",
+		},
+		{
+			name:     "anchor above gone, placed before the line below",
+			existing: "# hhclub:version 1\nFROM a\n# hhclub:keep\nRUN custom\n# hhclub:end\nRUN x\n",
+			new:      "# hhclub:version 1\nFROM b\nRUN y\nRUN x\n",
+			want:     "# hhclub:version 1\nFROM b\nRUN y\n# hhclub:keep\nRUN custom\n# hhclub:end\nRUN x\n",
+		},
+		{
+			name:      "anchor repeated in the new output",
+			existing:  "steps {\n}\n# hhclub:keep\nfoo\n# hhclub:end\n",
+			new:       "steps {\n}\nstage {\n}\n",
+			want:      "steps {\n}\nstage {\n}\n",
+			conflicts: 1,
+		},
+		{
+			name:     "top of file across a version bump",
+			existing: "# hhclub:keep\n# syntax=docker/dockerfile:1\n# hhclub:end\n# hhclub:version 4\nFROM a\n",
+			new:      "# hhclub:version 5\nFROM a\n",
+			want:     "# hhclub:keep\n# syntax=docker/dockerfile:1\n# hhclub:end\n# hhclub:version 5\nFROM a\n",
+		},
+		{
+			name:     "after the version marker across a version bump",
+			existing: "# hhclub:version 4\n# hhclub:keep\nARG EXTRA\n# hhclub:end\nFROM a\n",
+			new:      "# hhclub:version 5\nFROM b\n",
+			want:     "# hhclub:version 5\n# hhclub:keep\nARG EXTRA\n# hhclub:end\nFROM b\n",
+		},
+		{
+			name:     "no regions",
+			existing: "FROM a\n",
+			new:      "FROM b\n",
+			want:     "FROM b\n",
+		},
+		{name: "keep without end", existing: "a\n# hhclub:keep\nb\n", new: "a\n", wantErr: true},
+		{name: "end without keep", existing: "a\n# hhclub:end\n", new: "a\n", wantErr: true},
+		{name: "nested keep", existing: "# hhclub:keep\n# hhclub:keep\n# hhclub:end\n", new: "a\n", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, conflicts, err := MergeGenerated(tc.existing, tc.new)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("MergeGenerated() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeGenerated: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("MergeGenerated() =\n%s\nwant\n%s", got, tc.want)
+			}
+			if len(conflicts) != tc.conflicts {
+				t.Errorf("got %d conflicts %+v, want %d", len(conflicts), conflicts, tc.conflicts)
+			}
+			if tc.conflicts > 0 {
+				if c := conflicts[0]; c.Line != 3 || c.Region != "# hhclub:keep\nfoo\n# hhclub:end\n" {
+					t.Errorf("conflict = %+v, want line 3 and the kept region", c)
+				}
+				return
+			}
+
+			// Merging the result again with the same output changes nothing.
+			again, conflicts, err := MergeGenerated(got, tc.new)
+			if err != nil || len(conflicts) > 0 || again != got {
+				t.Errorf("re-merge = %q, %v, %v; want %q unchanged", again, conflicts, err, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+)
+
+// OverlayFS stacks file systems so that a path resolves to the first layer
+// that has it. Directories are merged: reading one lists its entries across
+// all layers, with earlier layers winning on name collisions. Put the
+// directory that should take precedence first:
+//
+//	render.OverlayFS(os.DirFS("team-templates"), os.DirFS("org-templates"), render.Embedded())
+func OverlayFS(layers ...fs.FS) fs.FS {
+	return overlayFS(append([]fs.FS(nil), layers...))
+}
+
+type overlayFS []fs.FS
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	for _, l := range o {
+		f, err := l.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var (
+		entries []fs.DirEntry
+		seen    = map[string]bool{}
+		found   bool
+	)
+	for _, l := range o {
+		des, err := fs.ReadDir(l, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, de := range des {
+			if !seen[de.Name()] {
+				seen[de.Name()] = true
+				entries = append(entries, de)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+// maxPartialDepth bounds nested includes so a partial that includes itself
+// fails instead of recursing forever.
+const maxPartialDepth = 8
+
+// partialTag matches {{> partials/NAME }} and captures the partial path.
+func (d delims) partialTag() *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(d.left) + `\s*>\s*(partials/[\w./-]+)\s*` + regexp.QuoteMeta(d.right))
+}
+
+// expandPartials replaces each include tag with the contents of
+// partials/NAME.tmpl in fsys, itself expanded, minus its final newline so a
+// partial can be used mid-line.
+func expandPartials(fsys fs.FS, src string, d delims, depth int) (string, error) {
+	tag := d.partialTag()
+	var firstErr error
+	out := tag.ReplaceAllStringFunc(src, func(m string) string {
+		if firstErr != nil {
+			return m
+		}
+		name := tag.FindStringSubmatch(m)[1]
+		if depth >= maxPartialDepth {
+			firstErr = fmt.Errorf("partial %s: includes nested deeper than %d, possibly recursive", name, maxPartialDepth)
+			return m
+		}
+		b, err := fs.ReadFile(fsys, name+templateExt)
+		if err != nil {
+			firstErr = fmt.Errorf("partial %s: %w", name, err)
+			return m
+		}
+		body, err := expandPartials(fsys, strings.TrimSuffix(string(b), "\n"), d, depth+1)
+		if err != nil {
+			firstErr = err
+			return m
+		}
+		return body
+	})
+	return out, firstErr
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExpandPartials(t *testing.T) {
+	d := delims{defaultLeftDelim, defaultRightDelim}
+	fsys := fstest.MapFS{
+		"partials/flags.tmpl": {Data: []byte("-v {{> partials/race }}\n")},
+		"partials/race.tmpl":  {Data: []byte("-race\n")},
+		"partials/steps.tmpl": {Data: []byte("a\nb\n")},
+		"partials/self.tmpl":  {Data: []byte("x {{> partials/self }}\n")},
+		"partials/ping.tmpl":  {Data: []byte("{{> partials/pong }}")},
+		"partials/pong.tmpl":  {Data: []byte("{{> partials/ping }}")},
+	}
+
+	for _, tc := range []struct{ name, src, want string }{
+		{"mid-line, nested", "go test {{> partials/flags }} ./...\n", "go test -v -race ./...\n"},
+		{"spacing in the tag", "go test {{>partials/race}} ./...\n", "go test -race ./...\n"},
+		{"whole lines", "start\n{{> partials/steps }}\nend\n", "start\na\nb\nend\n"},
+		{"no partials", "{{BUILD_CMD}}\n", "{{BUILD_CMD}}\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandPartials(fsys, tc.src, d, 0)
+			if err != nil {
+				t.Fatalf("expandPartials: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expandPartials() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	for _, tc := range []struct{ name, src, wantErr string }{
+		{"self-including", "{{> partials/self }}\n", "possibly recursive"},
+		{"mutually recursive", "{{> partials/ping }}\n", "possibly recursive"},
+		{"missing", "a {{> partials/nope }}\n", "partial partials/nope"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := expandPartials(fsys, tc.src, d, 0)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expandPartials() error = %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	// Templates with other delimiters include partials with them.
+	got, err := expandPartials(fstest.MapFS{"partials/race.tmpl": {Data: []byte("-race\n")}}, "go test [[> partials/race ]]\n", delims{"[[", "]]"}, 0)
+	if err != nil || got != "go test -race\n" {
+		t.Errorf("expandPartials([[ ]]) = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,105 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// A Plan describes what RenderAll would do in the working directory without
+// doing it. It marshals to JSON for review or an approval gate.
+type Plan struct {
+	Files []PlannedFile `json:"files"`
+}
+
+// PlannedFile is one file of a Plan.
+type PlannedFile struct {
+	Template string `json:"template"`
+	// Path is relative to the working directory, e.g. ".github/workflows/ci.yml".
+	Path string `json:"path"`
+	// Size is the rendered size in bytes.
+	Size int `json:"size"`
+	// Action is PlanCreate or PlanSkip.
+	Action string `json:"action"`
+	// Companion is set for files added by another template, such as the
+	// .dockerignore that comes with a Dockerfile.
+	Companion bool `json:"companion,omitempty"`
+	// Vars holds the value of every variable the template refers to, after
+	// defaults are applied.
+	Vars map[string]string `json:"vars"`
+}
+
+// Plan actions.
+const (
+	PlanCreate = "create"
+	// PlanSkip marks a companion whose file already exists and is kept.
+	PlanSkip = "skip"
+)
+
+// RenderPlan renders templateNames like RenderAll would into the working
+// directory and returns a plan of the files it would write. Nothing is
+// written. Missing variables, a Dockerfile older than ./go.mod, two
+// templates writing the same path and an existing file the caller named fail
+// the plan with the error they would fail RenderAll with; keys no template
+// refers to are returned as an ErrUnknownVars warning alongside the plan.
+//
+// Unlike DiffTemplate, which shows line changes for one file, a plan
+// summarizes the whole operation.
+func RenderPlan(templateNames []string, vars map[string]string) (Plan, error) {
+	files, err := renderSet(templateNames, projectFiles, vars)
+	if files == nil {
+		return Plan{}, err
+	}
+	if cerr := checkGoVersion(".", files); cerr != nil {
+		return Plan{}, cerr
+	}
+	plan := Plan{Files: make([]PlannedFile, 0, len(files))}
+	for _, f := range files {
+		action := PlanCreate
+		p := filepath.FromSlash(f.path)
+		if _, serr := os.Lstat(p); serr == nil {
+			if !f.companion {
+				return Plan{}, fmt.Errorf("render: write %s: %w", p, fs.ErrExist)
+			}
+			action = PlanSkip
+		} else if !errors.Is(serr, fs.ErrNotExist) {
+			return Plan{}, fmt.Errorf("render: plan %s: %w", f.path, serr)
+		}
+		used, uerr := usedVars(f.template, vars)
+		if uerr != nil {
+			return Plan{}, uerr
+		}
+		plan.Files = append(plan.Files, PlannedFile{
+			Template:  f.template,
+			Path:      f.path,
+			Size:      len(f.content),
+			Action:    action,
+			Companion: f.companion,
+			Vars:      used,
+		})
+	}
+	return plan, err
+}
+
+// usedVars returns the values the named template sees for the keys it
+// refers to: vars layered over its defaults and derived keys.
+func usedVars(name string, vars map[string]string) (map[string]string, error) {
+	e, d, src, err := std.load(name, RenderOptions{})
+	if err != nil {
+		return nil, err
+	}
+	derived, err := derivedVars(e.defaults, vars)
+	if err != nil {
+		return nil, fmt.Errorf("render: %s: %w", name, err)
+	}
+	all := MergeVars(e.defaults, derived, vars)
+	used := map[string]string{}
+	for k := range knownKeys(src, d) {
+		if v, ok := all[k]; ok {
+			used[k] = v
+		}
+	}
+	return used, nil
+}
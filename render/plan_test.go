@@ -0,0 +1,68 @@
+package render
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+// chdir changes to dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestRenderPlan(t *testing.T) {
+	vars := map[string]string{"BUILD_CMD": "go build ./..."}
+	chdir(t, t.TempDir())
+
+	plan, err := RenderPlan([]string{"make/Makefile"}, vars)
+	if err != nil {
+		t.Fatalf("RenderPlan: %v", err)
+	}
+	want := map[string]string{"Makefile": PlanCreate, ".gitignore": PlanCreate}
+	if len(plan.Files) != len(want) {
+		t.Fatalf("planned %+v, want %v", plan.Files, want)
+	}
+	for _, f := range plan.Files {
+		if want[f.Path] != f.Action || f.Size == 0 {
+			t.Errorf("planned %s: action %q size %d, want %q", f.Path, f.Action, f.Size, want[f.Path])
+		}
+	}
+	if got := plan.Files[0].Vars["BUILD_CMD"]; got != "go build ./..." {
+		t.Errorf("Makefile vars BUILD_CMD = %q", got)
+	}
+
+	// An existing companion is kept.
+	if err := os.WriteFile(".gitignore", []byte("bin/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = RenderPlan([]string{"make/Makefile"}, vars)
+	if err != nil {
+		t.Fatalf("RenderPlan with .gitignore: %v", err)
+	}
+	for _, f := range plan.Files {
+		if f.Path == ".gitignore" && (f.Action != PlanSkip || !f.Companion) {
+			t.Errorf("existing .gitignore planned as %+v, want a skipped companion", f)
+		}
+	}
+
+	// An existing file the caller named fails the plan as it fails RenderAll.
+	if err := os.WriteFile("Makefile", []byte("all:\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenderPlan([]string{"make/Makefile"}, vars); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("RenderPlan with Makefile = %v, want fs.ErrExist", err)
+	}
+	if _, err := RenderAll(".", []string{"make/Makefile"}, vars); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("RenderAll with Makefile = %v, want fs.ErrExist", err)
+	}
+}
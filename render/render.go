@@ -0,0 +1,240 @@
+// Package render fills in the {{KEY}} placeholders carried by the shipped
+// Dockerfile and CI templates.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrUnsubstituted is wrapped by the error RenderTemplate returns when a
+// placeholder in the template had no value in vars. The error is a
+// *MissingVarsError.
+var ErrUnsubstituted = errors.New("unsubstituted placeholders")
+
+// ErrUnknownVars is wrapped by the error RenderTemplate returns when vars
+// holds keys the template never references. It is a warning: the rendered
+// output is complete and still returned.
+var ErrUnknownVars = errors.New("unknown variables")
+
+// rendered reports whether a render that returned err still produced its
+// output, which may legitimately be empty: err is nil or only an
+// ErrUnknownVars warning.
+func rendered(err error) bool {
+	var verr *ValidationError
+	return err == nil || errors.Is(err, ErrUnknownVars) && !errors.Is(err, ErrUnsubstituted) && !errors.As(err, &verr)
+}
+
+// RenderOptions adjusts how RenderTemplateWith finds placeholders and
+// checks its output.
+type RenderOptions struct {
+	// LeftDelim and RightDelim surround a placeholder key, e.g. "<<" and
+	// ">>" or "${" and "}". Left empty, the template's own delimiters are
+	// used, which is "{{" and "}}" for all shipped templates.
+	LeftDelim  string
+	RightDelim string
+	// Validate lints the output of templates that write a Dockerfile with
+	// ValidateDockerfile and fails the render with a *ValidationError on any
+	// issue.
+	Validate bool
+	// Verify checks the output with VerifyRendered and fails the render with
+	// a *ValidationError if a value broke its YAML or Groovy syntax.
+	Verify bool
+}
+
+const (
+	defaultLeftDelim  = "{{"
+	defaultRightDelim = "}}"
+)
+
+type delims struct {
+	left, right string
+}
+
+// pattern matches a placeholder between the delimiters and captures its key
+// and optional filter name, as in {{KEY}} or {{KEY|yaml}}.
+func (d delims) pattern() *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(d.left) + `\s*([A-Z][A-Z0-9_]*)\s*(?:\|\s*(\w+)\s*)?` + regexp.QuoteMeta(d.right))
+}
+
+type entry struct {
+	name string
+	file string
+	// out is where RenderAll writes the result, relative to the project root.
+	out string
+	// defaults are read on load from the shared defaults.yaml and the
+	// template's own .defaults.yaml file.
+	defaults map[string]string
+	// left and right override the default "{{" "}}" placeholder delimiters.
+	left, right string
+	// script marks templates whose output is an executable script.
+	script bool
+	// companions are rendered by RenderAll alongside this template.
+	companions []string
+}
+
+func (e entry) mode() fs.FileMode {
+	if e.script {
+		return 0o755
+	}
+	return 0o644
+}
+
+func (e entry) delims(opts RenderOptions) delims {
+	d := delims{left: defaultLeftDelim, right: defaultRightDelim}
+	if e.left != "" {
+		d = delims{left: e.left, right: e.right}
+	}
+	if opts.LeftDelim != "" {
+		d.left = opts.LeftDelim
+	}
+	if opts.RightDelim != "" {
+		d.right = opts.RightDelim
+	}
+	return d
+}
+
+// registry maps canonical template names to their files in the embedded
+// templates directory. Each template's own defaults live next to it in
+// <file>.defaults.yaml, e.g. k8s/deployment.yml.defaults.yaml, over the
+// shared ones in defaults.yaml.
+var registry = []entry{
+	{name: "docker/Dockerfile", file: "docker/Dockerfile.tmpl", out: "Dockerfile", companions: []string{"docker/dockerignore", "make/Makefile"}},
+	{name: "docker/Dockerfile-test", file: "docker/Dockerfile.test.tmpl", out: "Dockerfile", companions: []string{"docker/dockerignore", "make/Makefile"}},
+	{name: "docker/dockerignore", file: "docker/dockerignore.tmpl", out: ".dockerignore"},
+	{name: "git/gitignore", file: "git/gitignore.tmpl", out: ".gitignore"},
+	{name: "jenkins/Jenkinsfile", file: "jenkins/Jenkinsfile.tmpl", out: "Jenkinsfile", companions: []string{"golangci/golangci"}},
+	{name: "github/workflow", file: "github/workflow.yml.tmpl", out: ".github/workflows/ci.yml", companions: []string{"golangci/golangci"}},
+	{name: "gitlab/gitlab-ci", file: "gitlab/gitlab-ci.yml.tmpl", out: ".gitlab-ci.yml", companions: []string{"golangci/golangci"}},
+	{name: "azure/azure-pipelines", file: "azure/azure-pipelines.yml.tmpl", out: "azure-pipelines.yml", companions: []string{"golangci/golangci"}},
+	{name: "circleci/config", file: "circleci/config.yml.tmpl", out: ".circleci/config.yml"},
+	{name: "golangci/golangci", file: "golangci/golangci.yml.tmpl", out: ".golangci.yml"},
+	{name: "make/Makefile", file: "make/Makefile.tmpl", out: "Makefile"},
+	{name: "k8s/deployment", file: "k8s/deployment.yml.tmpl", out: "k8s/deployment.yml"},
+	{name: "k8s/service", file: "k8s/service.yml.tmpl", out: "k8s/service.yml"},
+
+	// Helm templates use {{ }} themselves, so the chart files mark our
+	// placeholders with [[ ]]. Their paths are relative to the chart root.
+	{name: "helm/Chart", file: "helm/Chart.yaml.tmpl", out: "Chart.yaml", left: "[[", right: "]]"},
+	{name: "helm/values", file: "helm/values.yaml.tmpl", out: "values.yaml", left: "[[", right: "]]"},
+	{name: "helm/deployment", file: "helm/deployment.yaml.tmpl", out: "templates/deployment.yaml", left: "[[", right: "]]"},
+	{name: "helm/service", file: "helm/service.yaml.tmpl", out: "templates/service.yaml", left: "[[", right: "]]"},
+}
+
+// DefaultVars returns the values the renderer uses for the named template's
+// placeholders when the caller leaves them out. The map is a copy and may be
+// modified.
+func DefaultVars(templateName string) map[string]string {
+	return std.DefaultVars(templateName)
+}
+
+// RenderTemplate loads the named template (e.g. "docker/Dockerfile") and
+// replaces every {{KEY}} with vars["KEY"]. Partials referenced as
+// {{> partials/NAME }} are spliced in first. A region wrapped in
+// {{#if KEY}} ... {{/if}} is kept only when KEY is set and not false,
+// {{#unless KEY}} ... {{/unless}} only when it is not, and one wrapped in
+// {{#each KEY}} ... {{/each}} is repeated for each item of the
+// comma-separated list in KEY. A placeholder written {{KEY|FILTER}} passes
+// its value through a filter: |yaml quotes it as a YAML scalar when needed,
+// |shell escapes it for a Jenkinsfile's sh '...' and |lower and |upper change
+// its case. Keys missing from vars fall back to DefaultVars(name), so a
+// caller passing nothing still gets e.g. the default GO_VERSION in the
+// Dockerfile.
+//
+// If a placeholder has neither a value nor a default the result is empty and
+// the error wraps ErrUnsubstituted, listing the tokens. If vars holds keys
+// the template does not use, the rendered text is returned together with an
+// error wrapping ErrUnknownVars so typos get noticed.
+func RenderTemplate(name string, vars map[string]string) (string, error) {
+	return std.Render(name, vars)
+}
+
+// RenderTemplateWith is RenderTemplate with placeholder delimiters taken
+// from opts, for output formats where "{{ }}" means something else.
+func RenderTemplateWith(name string, vars map[string]string, opts RenderOptions) (string, error) {
+	return std.RenderWith(name, vars, opts)
+}
+
+func substitute(name, src string, d delims, defaults, vars map[string]string) (string, error) {
+	value := lookupVar(defaults, vars)
+	body, err := expandBlocks(expandVersion(src, d), d, func(key string) string { v, _ := value(key); return v })
+	if err != nil {
+		return "", fmt.Errorf("render: %s: %w", name, err)
+	}
+
+	pattern := d.pattern()
+	seen := map[string]bool{}
+	var (
+		missing []string
+		ferr    error
+	)
+	out := pattern.ReplaceAllStringFunc(body, func(m string) string {
+		sub := pattern.FindStringSubmatch(m)
+		key, filter := sub[1], sub[2]
+		v, ok := value(key)
+		if !ok {
+			if !seen[key] {
+				missing = append(missing, key)
+			}
+			seen[key] = true
+			return m
+		}
+		if filter == "" {
+			return v
+		}
+		f, ok := filters[filter]
+		if !ok {
+			ferr = fmt.Errorf("render: %s: unknown filter %q on %s", name, filter, key)
+			return m
+		}
+		return f(v)
+	})
+	if ferr != nil {
+		return "", ferr
+	}
+	if len(missing) > 0 {
+		return "", &MissingVarsError{Template: name, Vars: missing}
+	}
+
+	// Keys used only inside a dropped block or as a condition are still
+	// known to the template.
+	known := knownKeys(src, d)
+	var unknown []string
+	for k := range vars {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return out, fmt.Errorf("render: %s: %w: %s", name, ErrUnknownVars, strings.Join(unknown, ", "))
+	}
+	return out, nil
+}
+
+// knownKeys returns every key src refers to, as a placeholder or a condition.
+func knownKeys(src string, d delims) map[string]bool {
+	known := map[string]bool{}
+	for _, k := range tokens(src, d) {
+		known[k] = true
+	}
+	for _, k := range conditions(src, d) {
+		known[k] = true
+	}
+	return known
+}
+
+// lookupVar resolves a key from vars, falling back to defaults.
+func lookupVar(defaults, vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		if v, ok := vars[key]; ok {
+			return v, true
+		}
+		v, ok := defaults[key]
+		return v, ok
+	}
+}
@@ -0,0 +1,220 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMakefileRecipesUseTabs(t *testing.T) {
+	out, err := RenderTemplate("make/Makefile", map[string]string{"BUILD_CMD": "go build ./cmd/api"})
+	if err != nil {
+		t.Fatalf("RenderTemplate: %v", err)
+	}
+	recipes := 0
+	inRule := false
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case line == "":
+			inRule = false
+		case strings.HasSuffix(line, ":") && !strings.HasPrefix(line, "\t"):
+			inRule = true
+		case inRule:
+			if !strings.HasPrefix(line, "\t") {
+				t.Errorf("recipe line %q does not start with a tab", line)
+			}
+			recipes++
+		}
+	}
+	if recipes == 0 {
+		t.Fatalf("no recipe lines found in:\n%s", out)
+	}
+	if !strings.Contains(out, "build:\n\tgo build ./cmd/api\n") {
+		t.Errorf("build recipe not rendered with a tab:\n%s", out)
+	}
+}
+
+func TestDockerfileHealthcheck(t *testing.T) {
+	for _, name := range []string{"docker/Dockerfile", "docker/Dockerfile-test"} {
+		t.Run(name, func(t *testing.T) {
+			opts := RenderOptions{Validate: true}
+			out, err := RenderTemplateWith(name, map[string]string{
+				"HEALTHCHECK_CMD":  "wget -qO- http://127.0.0.1:8080/healthz || exit 1",
+				"HEALTHCHECK_PORT": "8080",
+			}, opts)
+			if err != nil {
+				t.Fatalf("with healthcheck: %v", err)
+			}
+			hc := strings.Index(out, "HEALTHCHECK --interval=30s CMD wget -qO- http://127.0.0.1:8080/healthz || exit 1\n")
+			if hc < 0 {
+				t.Fatalf("HEALTHCHECK missing:\n%s", out)
+			}
+			if !strings.Contains(out, "EXPOSE 8080\n") {
+				t.Errorf("EXPOSE for the healthcheck port missing:\n%s", out)
+			}
+			if ep := strings.Index(out, "ENTRYPOINT"); ep < hc {
+				t.Errorf("HEALTHCHECK after ENTRYPOINT:\n%s", out)
+			}
+
+			out, err = RenderTemplateWith(name, nil, opts)
+			if err != nil {
+				t.Fatalf("without healthcheck: %v", err)
+			}
+			if strings.Contains(out, "HEALTHCHECK") || strings.Contains(out, "EXPOSE") {
+				t.Errorf("healthcheck rendered without HEALTHCHECK_CMD:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestFiltersKeepHostileValuesIntact(t *testing.T) {
+	vars := map[string]string{
+		"IMAGE_NAME": "team/app: beta #1",
+		"BUILD_CMD":  `go build -ldflags='-X main.v=1' ./... # it's "fast"`,
+		"TEST_CMD":   "go test ./...",
+		"LINT_CMD":   `golangci-lint run --out-format 'colored-line-number' ./... #note: slow`,
+		"SONAR_HOST": "https://sonar.example.com/#a: b's",
+		"REGISTRY":   "registry.example.com",
+	}
+	for _, tc := range []struct {
+		name string
+		// keys whose values must read back from the YAML unchanged
+		yamlKeys []string
+	}{
+		{"github/workflow", []string{"IMAGE_NAME", "BUILD_CMD", "LINT_CMD", "SONAR_HOST"}},
+		{"gitlab/gitlab-ci", []string{"IMAGE_NAME", "BUILD_CMD", "LINT_CMD"}},
+		{"azure/azure-pipelines", []string{"IMAGE_NAME", "BUILD_CMD", "LINT_CMD"}},
+		{"circleci/config", []string{"IMAGE_NAME", "BUILD_CMD"}},
+		{"jenkins/Jenkinsfile", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := RenderTemplateWith(tc.name, vars, RenderOptions{Verify: true})
+			if err != nil && !errors.Is(err, ErrUnknownVars) {
+				t.Fatalf("RenderTemplateWith(Verify): %v", err)
+			}
+			var doc yaml.Node
+			if tc.yamlKeys != nil {
+				if err := yaml.Unmarshal([]byte(out), &doc); err != nil {
+					t.Fatalf("output is not YAML: %v", err)
+				}
+			}
+			for _, k := range tc.yamlKeys {
+				if !yamlHasScalar(&doc, vars[k]) {
+					t.Errorf("%s %q does not read back from:\n%s", k, vars[k], out)
+				}
+			}
+		})
+	}
+	out, err := RenderTemplate("jenkins/Jenkinsfile", vars)
+	if err != nil && !errors.Is(err, ErrUnknownVars) {
+		t.Fatalf("RenderTemplate(jenkins/Jenkinsfile): %v", err)
+	}
+	if want := `SONAR_HOST = 'https://sonar.example.com/#a: b\'s'`; !strings.Contains(out, want) {
+		t.Errorf("Jenkinsfile lacks %s:\n%s", want, out)
+	}
+	if got, want := filters["shell"](`echo 'a\b'`), `echo \'a\\b\'`; got != want {
+		t.Errorf("shell filter = %s, want %s", got, want)
+	}
+	for v, want := range map[string]string{"go test ./...": "go test ./...", "1.20": `"1.20"`, "a: b": `"a: b"`, "": `""`} {
+		if got := filters["yaml"](v); got != want {
+			t.Errorf("yaml filter(%q) = %s, want %s", v, got, want)
+		}
+	}
+}
+
+func TestEmptyRegistryPushesUnprefixedImage(t *testing.T) {
+	vars := map[string]string{
+		"IMAGE_NAME": "app",
+		"BUILD_CMD":  "go build ./...",
+		"TEST_CMD":   "go test ./...",
+		"SONAR_HOST": "sonar.example.com",
+		"REGISTRY":   "",
+	}
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"github/workflow", "docker build -t ${IMAGE}:"},
+		{"azure/azure-pipelines", "repository: '$(IMAGE)'"},
+		{"gitlab/gitlab-ci", `IMAGE_REF: "$CI_REGISTRY_IMAGE/$IMAGE"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, platforms := range []string{"", "linux/amd64,linux/arm64"} {
+				out, err := RenderTemplateWith(tc.name, MergeVars(vars, map[string]string{"PLATFORMS": platforms}), RenderOptions{Verify: true})
+				if err != nil && !errors.Is(err, ErrUnknownVars) {
+					t.Fatalf("RenderTemplateWith(PLATFORMS=%q): %v", platforms, err)
+				}
+				for _, bad := range []string{" /$", "'/$", "registry: \n", "registry:''"} {
+					if strings.Contains(out, bad) {
+						t.Errorf("PLATFORMS=%q: output contains %q for an empty REGISTRY:\n%s", platforms, bad, out)
+					}
+				}
+				if platforms == "" && !strings.Contains(out, tc.want) {
+					t.Errorf("output lacks %q:\n%s", tc.want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestExcludeTestsRejectedWithTestDockerfile(t *testing.T) {
+	vars := map[string]string{"BUILD_CMD": "go build ./...", "EXCLUDE_TESTS": "true"}
+	if _, err := RenderToMap([]string{"docker/Dockerfile-test"}, vars); err == nil || !strings.Contains(err.Error(), "EXCLUDE_TESTS") {
+		t.Errorf("RenderToMap(Dockerfile-test, EXCLUDE_TESTS) error = %v, want an EXCLUDE_TESTS error", err)
+	}
+	out, err := RenderToMap([]string{"docker/Dockerfile"}, vars)
+	if err != nil {
+		t.Fatalf("RenderToMap(Dockerfile, EXCLUDE_TESTS): %v", err)
+	}
+	if !strings.Contains(out[".dockerignore"], "**/*_test.go") {
+		t.Errorf(".dockerignore does not exclude tests:\n%s", out[".dockerignore"])
+	}
+}
+
+func TestLintDoesNotBuildGolangciLint(t *testing.T) {
+	vars := map[string]string{
+		"IMAGE_NAME": "app",
+		"BUILD_CMD":  "go build ./...",
+		"TEST_CMD":   "go test ./...",
+		"SONAR_HOST": "sonar.example.com",
+	}
+	for _, name := range []string{"github/workflow", "azure/azure-pipelines"} {
+		t.Run(name, func(t *testing.T) {
+			out, err := RenderTemplateWith(name, vars, RenderOptions{Verify: true})
+			if err != nil && !errors.Is(err, ErrUnknownVars) {
+				t.Fatalf("RenderTemplateWith: %v", err)
+			}
+			if strings.Contains(out, "go install github.com/golangci") {
+				t.Errorf("golangci-lint is built with GO_VERSION:\n%s", out)
+			}
+			if !strings.Contains(out, "golangci/golangci-lint:v1.59.1") {
+				t.Errorf("lint does not run the golangci-lint image:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestValidateSkipsDockerignore(t *testing.T) {
+	out, err := RenderTemplateWith("docker/dockerignore", nil, RenderOptions{Validate: true})
+	if err != nil {
+		t.Fatalf("RenderTemplateWith(dockerignore, Validate): %v", err)
+	}
+	if !strings.Contains(out, ".git") {
+		t.Errorf("unexpected .dockerignore:\n%s", out)
+	}
+}
+
+// yamlHasScalar reports whether a scalar under n contains v.
+func yamlHasScalar(n *yaml.Node, v string) bool {
+	if n.Kind == yaml.ScalarNode && strings.Contains(n.Value, v) {
+		return true
+	}
+	for _, c := range n.Content {
+		if yamlHasScalar(c, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	hhclub "github.com/loufp/hhClub"
+)
+
+// A Renderer renders templates read from a file system laid out like the
+// shipped templates directory: <category>/<file>.tmpl, with shared includes
+// under partials/. The package-level functions use a Renderer over
+// Embedded().
+//
+// A file at the path of a shipped template, such as
+// jenkins/Jenkinsfile.tmpl, replaces that template and keeps its defaults,
+// delimiters and output path. Any other .tmpl file outside partials/ is a
+// template of its own, named by its path without the extension, so
+// jenkins/Jenkinsfile.private.tmpl renders as "jenkins/Jenkinsfile.private".
+// Such templates use "{{" "}}" and are written under their base name.
+//
+// Every template takes its defaults from a <file>.defaults.yaml next to it,
+// if there is one: jenkins/Jenkinsfile.private.defaults.yaml for the
+// template above. A defaults.yaml at the root holds the defaults templates
+// share, such as GO_VERSION; each template takes the keys it refers to, and
+// its own file wins over them.
+//
+// To keep the shipped templates while adding or replacing some, stack a
+// directory over them with OverlayFS:
+//
+//	r := render.NewRenderer(render.OverlayFS(os.DirFS("ci-templates"), render.Embedded()))
+type Renderer struct {
+	fsys fs.FS
+}
+
+// NewRenderer returns a Renderer reading templates from fsys.
+func NewRenderer(fsys fs.FS) *Renderer {
+	return &Renderer{fsys: fsys}
+}
+
+// Embedded returns the shipped templates directory as an fs.FS rooted at
+// the templates themselves, e.g. "docker/Dockerfile.tmpl".
+func Embedded() fs.FS {
+	sub, err := fs.Sub(hhclub.FS, "templates")
+	if err != nil {
+		panic(err) // the directory is embedded at build time
+	}
+	return sub
+}
+
+// std backs the package-level functions.
+var std = NewRenderer(Embedded())
+
+const templateExt = ".tmpl"
+
+// lookup finds the registry entry for name, or synthesizes one for a .tmpl
+// file in r's file system that no registry entry claims. Either way the
+// file has to be in r's file system.
+func (r *Renderer) lookup(name string) (entry, bool) {
+	for _, e := range registry {
+		if e.name == name {
+			return e, r.isFile(e.file)
+		}
+	}
+	file := name + templateExt
+	if !fs.ValidPath(file) || strings.HasPrefix(file, "partials/") || claimed(file) || !r.isFile(file) {
+		return entry{}, false
+	}
+	return entry{name: name, file: file, out: path.Base(name)}, true
+}
+
+// isFile reports whether r's file system has a regular file at file.
+func (r *Renderer) isFile(file string) bool {
+	st, err := fs.Stat(r.fsys, file)
+	return err == nil && !st.IsDir()
+}
+
+// claimed reports whether a registry entry reads file.
+func claimed(file string) bool {
+	for _, e := range registry {
+		if e.file == file {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the named template and splices in its partials, returning the
+// delimiters opts resolves to for it.
+func (r *Renderer) load(name string, opts RenderOptions) (entry, delims, string, error) {
+	e, ok := r.lookup(name)
+	if !ok {
+		return e, delims{}, "", &UnknownTemplateError{Name: name}
+	}
+	d := e.delims(opts)
+	b, err := fs.ReadFile(r.fsys, e.file)
+	if err != nil {
+		return e, d, "", fmt.Errorf("render: load %s: %w", name, err)
+	}
+	src, err := expandPartials(r.fsys, string(b), d, 0)
+	if err != nil {
+		return e, d, "", fmt.Errorf("render: %s: %w", name, err)
+	}
+	if e.defaults, err = r.defaults(e.file, knownKeys(src, d)); err != nil {
+		return e, d, "", err
+	}
+	return e, d, src, nil
+}
+
+// defaultsExt names the defaults file that sits next to a template:
+// docker/Dockerfile.test.tmpl takes its defaults from
+// docker/Dockerfile.test.defaults.yaml.
+const defaultsExt = ".defaults.yaml"
+
+// sharedDefaults holds the defaults common to all templates, such as
+// GO_VERSION, at the root of the templates directory.
+const sharedDefaults = "defaults.yaml"
+
+// defaults returns the defaults for the template at file: the shared
+// defaults for the keys it knows, overlaid with its own defaults file.
+// Either file may be missing.
+func (r *Renderer) defaults(file string, known map[string]bool) (map[string]string, error) {
+	shared, err := r.readDefaults(sharedDefaults)
+	if err != nil {
+		return nil, err
+	}
+	for k := range shared {
+		if !known[k] {
+			delete(shared, k)
+		}
+	}
+	own, err := r.readDefaults(strings.TrimSuffix(file, templateExt) + defaultsExt)
+	if err != nil {
+		return nil, err
+	}
+	return MergeVars(shared, own), nil
+}
+
+// readDefaults parses the defaults file at p, or returns an empty map when
+// there is none.
+func (r *Renderer) readDefaults(p string) (map[string]string, error) {
+	b, err := fs.ReadFile(r.fsys, p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render: load defaults: %w", err)
+	}
+	return parseVars(p, b)
+}
+
+// DefaultVars is the package-level DefaultVars for r's templates.
+func (r *Renderer) DefaultVars(templateName string) map[string]string {
+	e, _, _, err := r.load(templateName, RenderOptions{})
+	if err != nil {
+		return map[string]string{}
+	}
+	return e.defaults
+}
+
+// Render is RenderTemplate for r's templates.
+func (r *Renderer) Render(name string, vars map[string]string) (string, error) {
+	return r.RenderWith(name, vars, RenderOptions{})
+}
+
+// RenderWith is RenderTemplateWith for r's templates.
+func (r *Renderer) RenderWith(name string, vars map[string]string, opts RenderOptions) (string, error) {
+	e, d, src, err := r.load(name, opts)
+	if err != nil {
+		return "", err
+	}
+	derived, err := derivedVars(e.defaults, vars)
+	if err != nil {
+		return "", fmt.Errorf("render: %s: %w", name, err)
+	}
+	if err := checkIDs(src, d, e.defaults, vars); err != nil {
+		return "", fmt.Errorf("render: %s: %w", name, err)
+	}
+	out, err := substitute(name, src, d, MergeVars(e.defaults, derived), vars)
+	if !rendered(err) {
+		return "", err
+	}
+	var issues []string
+	if opts.Validate && path.Base(e.out) == "Dockerfile" {
+		for _, issue := range ValidateDockerfile(out) {
+			issues = append(issues, issue.Error())
+		}
+	}
+	if opts.Verify {
+		issues = append(issues, verifyOutput(e, out, vars)...)
+	}
+	if len(issues) == 0 {
+		return out, err
+	}
+	verr := &ValidationError{Template: name, Issues: issues}
+	if err != nil {
+		return "", errors.Join(verr, err)
+	}
+	return "", verr
+}
+
+// List is ListTemplates for r's templates: the shipped templates present in
+// r's file system, followed by its other .tmpl files in lexical order.
+func (r *Renderer) List() []TemplateInfo {
+	var infos []TemplateInfo
+	add := func(name string) {
+		info := TemplateInfo{Name: name, Category: category(name)}
+		if _, d, src, err := r.load(name, RenderOptions{}); err == nil {
+			info.Tokens = tokens(src, d)
+		}
+		infos = append(infos, info)
+	}
+	for _, e := range registry {
+		if r.isFile(e.file) {
+			add(e.name)
+		}
+	}
+	fs.WalkDir(r.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return nil
+		case d.IsDir() && p == "partials":
+			return fs.SkipDir
+		case !d.IsDir() && strings.HasSuffix(p, templateExt) && !claimed(p):
+			add(strings.TrimSuffix(p, templateExt))
+		}
+		return nil
+	})
+	return infos
+}
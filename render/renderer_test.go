@@ -0,0 +1,120 @@
+package render
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRendererUnknownTemplate(t *testing.T) {
+	r := NewRenderer(fstest.MapFS{"make/Makefile.tmpl": {Data: []byte("all:\n")}})
+	for _, name := range []string{"docker/Dockerfile", "nope/nope", "partials/sonar", "make/Makefile.tmpl"} {
+		_, err := r.Render(name, nil)
+		var uerr *UnknownTemplateError
+		if !errors.As(err, &uerr) || uerr.Name != name {
+			t.Errorf("Render(%q) error = %v, want an *UnknownTemplateError", name, err)
+		}
+	}
+	if out, err := r.Render("make/Makefile", nil); err != nil || out != "all:\n" {
+		t.Errorf(`Render("make/Makefile") = %q, %v`, out, err)
+	}
+	var names []string
+	for _, info := range r.List() {
+		names = append(names, info.Name)
+	}
+	if want := []string{"make/Makefile"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+}
+
+func TestOverlayFS(t *testing.T) {
+	top := fstest.MapFS{
+		"docker/Dockerfile.test.tmpl":  {Data: []byte("FROM golang:{{GO_VERSION}}\nRUN {{TEST_CMD}}\n")},
+		"docker/Dockerfile.extra.tmpl": {Data: []byte("FROM {{BASE}}\n")},
+		"git/gitignore.tmpl":           {Data: []byte("top\n")},
+	}
+	middle := fstest.MapFS{
+		"git/gitignore.tmpl": {Data: []byte("middle\n")},
+		"ci/steps.yml.tmpl":  {Data: []byte("steps: []\n")},
+	}
+	o := OverlayFS(top, middle, Embedded())
+
+	b, err := fs.ReadFile(o, "git/gitignore.tmpl")
+	if err != nil || string(b) != "top\n" {
+		t.Errorf("ReadFile(git/gitignore.tmpl) = %q, %v; want the first layer's", b, err)
+	}
+	if _, err := fs.ReadFile(o, "missing.tmpl"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile(missing.tmpl) error = %v, want fs.ErrNotExist", err)
+	}
+
+	entries, err := fs.ReadDir(o, "docker")
+	if err != nil {
+		t.Fatalf("ReadDir(docker): %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	for _, want := range []string{"Dockerfile.extra.tmpl", "Dockerfile.test.tmpl", "Dockerfile.test.defaults.yaml", "dockerignore.tmpl"} {
+		if !strings.Contains(strings.Join(names, " "), want) {
+			t.Errorf("ReadDir(docker) = %v, missing %s", names, want)
+		}
+	}
+	if strings.Count(strings.Join(names, " "), "Dockerfile.test.tmpl") != 1 {
+		t.Errorf("ReadDir(docker) lists Dockerfile.test.tmpl more than once: %v", names)
+	}
+	if _, err := fs.ReadDir(o, "nope"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadDir(nope) error = %v, want fs.ErrNotExist", err)
+	}
+
+	r := NewRenderer(o)
+	// A replaced shipped template keeps its own and the shared defaults.
+	out, err := r.Render("docker/Dockerfile-test", nil)
+	if err != nil {
+		t.Fatalf("Render(docker/Dockerfile-test): %v", err)
+	}
+	if want := "FROM golang:" + DefaultVars("docker/Dockerfile-test")["GO_VERSION"] + "\nRUN go test ./...\n"; out != want {
+		t.Errorf("replaced Dockerfile-test = %q, want %q", out, want)
+	}
+	if out, err := r.Render("docker/Dockerfile.extra", map[string]string{"BASE": "alpine"}); err != nil || out != "FROM alpine\n" {
+		t.Errorf("Render(docker/Dockerfile.extra) = %q, %v", out, err)
+	}
+	if out, err := r.Render("ci/steps.yml", nil); err != nil || out != "steps: []\n" {
+		t.Errorf("Render(ci/steps.yml) = %q, %v", out, err)
+	}
+	if _, err := r.Render("make/Makefile", map[string]string{"BUILD_CMD": "go build"}); err != nil {
+		t.Errorf("shipped template not reachable through the overlay: %v", err)
+	}
+}
+
+func TestSharedDefaults(t *testing.T) {
+	goVersion := DefaultVars("docker/Dockerfile")["GO_VERSION"]
+	if goVersion == "" {
+		t.Fatal("docker/Dockerfile has no GO_VERSION default")
+	}
+	for _, name := range []string{"docker/Dockerfile-test", "github/workflow", "gitlab/gitlab-ci", "azure/azure-pipelines", "circleci/config", "jenkins/Jenkinsfile", "golangci/golangci"} {
+		if got := DefaultVars(name)["GO_VERSION"]; got != goVersion {
+			t.Errorf("%s GO_VERSION default = %q, want the shared %q", name, got, goVersion)
+		}
+	}
+	// Templates take only the shared keys they refer to.
+	if d := DefaultVars("git/gitignore"); len(d) != 0 {
+		t.Errorf("git/gitignore defaults = %v, want none", d)
+	}
+	if _, ok := DefaultVars("k8s/deployment")["GO_VERSION"]; ok {
+		t.Error("k8s/deployment took GO_VERSION from the shared defaults")
+	}
+
+	// A template's own defaults win over the shared ones.
+	r := NewRenderer(fstest.MapFS{
+		"defaults.yaml":              {Data: []byte("GO_VERSION: \"1.20\"\nPLATFORMS: \"\"\n")},
+		"ci/build.yml.tmpl":          {Data: []byte("go: {{GO_VERSION}}\n")},
+		"ci/build.yml.defaults.yaml": {Data: []byte("GO_VERSION: \"1.22\"\n")},
+	})
+	if got := r.DefaultVars("ci/build.yml"); !reflect.DeepEqual(got, map[string]string{"GO_VERSION": "1.22"}) {
+		t.Errorf("DefaultVars(ci/build.yml) = %v, want its own GO_VERSION only", got)
+	}
+}
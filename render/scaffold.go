@@ -0,0 +1,277 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// renderedFile is one template rendered for a project scaffold.
+type renderedFile struct {
+	template string
+	path     string // relative to the project root
+	content  string
+	mode     fs.FileMode
+	// companion is set for files added because another template asked for
+	// them rather than because the caller named them.
+	companion bool
+}
+
+// projectFiles are companions of every project scaffold, whatever templates
+// it is made of.
+var projectFiles = []string{"git/gitignore"}
+
+// templateSet is a resolved set of templates sharing one set of variables.
+type templateSet struct {
+	entries   []entry
+	companion map[string]bool
+	known     map[string]bool // keys any template in the set refers to
+}
+
+// resolveSet resolves templateNames against one shared set of variables
+// without rendering them. All missing variables across the set are reported
+// together, joining one *MissingVarsError per template. The extra
+// companions, and those of the named templates, are added after them unless
+// already named or their path is taken.
+func resolveSet(templateNames, companions []string, vars map[string]string) (*templateSet, error) {
+	var (
+		set = &templateSet{
+			entries:   make([]entry, 0, len(templateNames)),
+			companion: map[string]bool{},
+			known:     map[string]bool{},
+		}
+		paths   = map[string]string{}
+		missing []error
+	)
+	names := append([]string(nil), templateNames...)
+	for _, c := range companions {
+		if !slices.Contains(names, c) {
+			names = append(names, c)
+			set.companion[c] = true
+		}
+	}
+	for i := 0; i < len(names); i++ {
+		name := names[i]
+		e, d, src, err := std.load(name, RenderOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := paths[e.out]; ok {
+			if set.companion[name] {
+				continue
+			}
+			return nil, fmt.Errorf("render: %s and %s both write %s", prev, name, e.out)
+		}
+		paths[e.out] = name
+		set.entries = append(set.entries, e)
+		for _, c := range e.companions {
+			if !slices.Contains(names, c) {
+				names = append(names, c)
+				set.companion[c] = true
+			}
+		}
+
+		m, err := MissingVars(name, vars)
+		if err != nil {
+			return nil, err
+		}
+		if len(m) > 0 {
+			missing = append(missing, &MissingVarsError{Template: name, Vars: m})
+		}
+		for k := range knownKeys(src, d) {
+			set.known[k] = true
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Join(missing...)
+	}
+	if err := set.checkExcludeTests(vars); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// checkExcludeTests rejects EXCLUDE_TESTS in a set that runs the tests in
+// the image: the .dockerignore would drop every _test.go from the build
+// context, and the test stage would pass without running any.
+func (s *templateSet) checkExcludeTests(vars map[string]string) error {
+	if !truthy(vars["EXCLUDE_TESTS"]) {
+		return nil
+	}
+	var tests, ignore bool
+	for _, e := range s.entries {
+		tests = tests || e.name == "docker/Dockerfile-test"
+		ignore = ignore || e.name == "docker/dockerignore"
+	}
+	if tests && ignore {
+		return errors.New("render: EXCLUDE_TESTS would keep the tests out of docker/Dockerfile-test's build context; unset it")
+	}
+	return nil
+}
+
+// render renders one entry of the set. Per-template unknown-variable
+// warnings are dropped in favour of the set-wide one from unknownVars.
+func (s *templateSet) render(e entry, vars map[string]string) (renderedFile, error) {
+	out, err := RenderTemplate(e.name, vars)
+	if !rendered(err) {
+		return renderedFile{}, err
+	}
+	return renderedFile{template: e.name, path: e.out, content: out, mode: e.mode(), companion: s.companion[e.name]}, nil
+}
+
+// unknownVars returns an ErrUnknownVars warning for the keys in vars that no
+// template in the set refers to, or nil.
+func (s *templateSet) unknownVars(vars map[string]string) error {
+	var unknown []string
+	for k := range vars {
+		if !s.known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("render: %w: %s", ErrUnknownVars, strings.Join(unknown, ", "))
+}
+
+// renderSet resolves and renders a whole set; see resolveSet. Keys unknown to
+// every template in the set are returned as an ErrUnknownVars warning
+// alongside the files.
+func renderSet(templateNames, companions []string, vars map[string]string) ([]renderedFile, error) {
+	set, err := resolveSet(templateNames, companions, vars)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]renderedFile, 0, len(set.entries))
+	for _, e := range set.entries {
+		f, err := set.render(e, vars)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, set.unknownVars(vars)
+}
+
+// RenderAll renders each named template with the same vars and writes it
+// under outDir at its conventional path, e.g. "Dockerfile" or
+// ".github/workflows/ci.yml". It returns the paths written.
+//
+// Templates can bring companions: every project gets a .gitignore, and
+// rendering a Dockerfile also writes a .dockerignore and a Makefile. A
+// companion whose file already exists is skipped so a hand-maintained one is
+// kept.
+//
+// When outDir already holds a go.mod, each rendered Dockerfile is checked
+// against it with CheckGoVersionConsistency first.
+//
+// Nothing is written if any template is missing variables, a Dockerfile is
+// older than the go.mod, or any file the caller asked for already exists.
+// Two templates that write the same path, such as both Dockerfile variants,
+// are rejected.
+func RenderAll(outDir string, templateNames []string, vars map[string]string) ([]string, error) {
+	return renderInto(outDir, templateNames, projectFiles, vars)
+}
+
+// renderInto is RenderAll with the set-wide companions given explicitly.
+func renderInto(outDir string, templateNames, companions []string, vars map[string]string) ([]string, error) {
+	files, err := renderSet(templateNames, companions, vars)
+	if files == nil {
+		return nil, err
+	}
+	if cerr := checkGoVersion(outDir, files); cerr != nil {
+		return nil, cerr
+	}
+	todo := files[:0]
+	for _, f := range files {
+		p := filepath.Join(outDir, filepath.FromSlash(f.path))
+		if _, serr := os.Lstat(p); serr == nil {
+			if f.companion {
+				continue
+			}
+			return nil, fmt.Errorf("render: write %s: %w", p, fs.ErrExist)
+		} else if !errors.Is(serr, fs.ErrNotExist) {
+			return nil, fmt.Errorf("render: write %s: %w", p, serr)
+		}
+		todo = append(todo, f)
+	}
+
+	written := make([]string, 0, len(todo))
+	for _, f := range todo {
+		p := filepath.Join(outDir, filepath.FromSlash(f.path))
+		if werr := writeFile(p, []byte(f.content), f.mode, false); werr != nil {
+			return written, werr
+		}
+		written = append(written, p)
+	}
+	return written, err
+}
+
+// checkGoVersion runs CheckGoVersionConsistency over the rendered
+// Dockerfiles when outDir has a go.mod.
+func checkGoVersion(outDir string, files []renderedFile) error {
+	goMod := filepath.Join(outDir, "go.mod")
+	if _, err := os.Stat(goMod); err != nil {
+		return nil
+	}
+	for _, f := range files {
+		if path.Base(f.path) != "Dockerfile" {
+			continue
+		}
+		if err := CheckGoVersionConsistency(f.content, goMod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderToMap renders templateNames like RenderAll but returns the output in
+// memory, keyed by the path RenderAll would write relative to outDir, e.g.
+// out["Dockerfile"] or out[".github/workflows/ci.yml"]. Companions are
+// included. Defaults, missing-variable checks and the ErrUnknownVars warning
+// behave exactly as in RenderAll; a nil map means nothing was rendered.
+func RenderToMap(templateNames []string, vars map[string]string) (map[string]string, error) {
+	files, err := renderSet(templateNames, projectFiles, vars)
+	if files == nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(files))
+	for _, f := range files {
+		out[f.path] = f.content
+	}
+	return out, err
+}
+
+// RenderStream renders templateNames like RenderAll, one at a time, and
+// passes each to fn as a reader together with its path relative to the
+// project root, e.g. "Dockerfile". Only one rendered file is held in memory
+// at a time, so a caller can stream the set to disk or over the network.
+//
+// Missing variables are checked across the whole set before fn is first
+// called, so a late template cannot fail after earlier ones were consumed.
+// RenderStream stops at the first error from fn and returns it. An
+// ErrUnknownVars warning is returned once every file has been streamed.
+func RenderStream(templateNames []string, vars map[string]string, fn func(name string, r io.Reader) error) error {
+	set, err := resolveSet(templateNames, projectFiles, vars)
+	if err != nil {
+		return err
+	}
+	for _, e := range set.entries {
+		f, err := set.render(e, vars)
+		if err != nil {
+			return err
+		}
+		if err := fn(f.path, strings.NewReader(f.content)); err != nil {
+			return err
+		}
+	}
+	return set.unknownVars(vars)
+}
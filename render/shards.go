@@ -0,0 +1,60 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// derivedVars computes the variables templates read that follow from others
+// rather than being set by the caller. They sit between vars and the
+// template defaults, so a caller can still override one explicitly. Only
+// the test sharding keys are derived today:
+//
+//   - TEST_SHARDED is "true" when TEST_SHARDS is above 1.
+//   - TEST_SHARD_INDEXES lists the shard numbers 1..TEST_SHARDS.
+//   - TEST_SHARD_CMD is TEST_CMD with its ./... replaced by the packages of
+//     shard $TEST_SHARD, which each CI job exports. A template that shards,
+//     which declares TEST_SHARDS in its defaults, fails when TEST_CMD does
+//     not end in ./... and TEST_SHARD_CMD is not given explicitly.
+func derivedVars(defaults, vars map[string]string) (map[string]string, error) {
+	value := lookupVar(defaults, vars)
+	derived := map[string]string{}
+
+	raw, ok := value("TEST_SHARDS")
+	if !ok || raw == "" {
+		return derived, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("TEST_SHARDS must be a positive integer, got %q", raw)
+	}
+	if n == 1 {
+		return derived, nil
+	}
+	indexes := make([]string, n)
+	for i := range indexes {
+		indexes[i] = strconv.Itoa(i + 1)
+	}
+	derived["TEST_SHARDED"] = "true"
+	derived["TEST_SHARD_INDEXES"] = strings.Join(indexes, ",")
+	if cmd, ok := value("TEST_CMD"); ok {
+		prefix, ok := strings.CutSuffix(cmd, " ./...")
+		if ok {
+			derived["TEST_SHARD_CMD"] = shardCmd(prefix, n)
+		} else if _, explicit := value("TEST_SHARD_CMD"); !explicit && defaults["TEST_SHARDS"] != "" {
+			return nil, errors.New("TEST_SHARDS needs a TEST_CMD ending in ./... or an explicit TEST_SHARD_CMD")
+		}
+	}
+	return derived, nil
+}
+
+// shardCmd runs the go test command prefix over every n-th package of
+// go list ./..., starting at package $TEST_SHARD. A shard left without
+// packages passes. Each shard writes its own coverage profile so parallel
+// shards sharing a workspace do not clobber one another.
+func shardCmd(prefix string, n int) string {
+	prefix = strings.ReplaceAll(prefix, "-coverprofile="+coverageProfile, "-coverprofile=coverage-$TEST_SHARD.out")
+	return fmt.Sprintf(`pkgs=$(go list ./... | awk -v n=%d -v i="$TEST_SHARD" "(NR - 1) %% n + 1 == i"); [ -z "$pkgs" ] || %s $pkgs`, n, prefix)
+}
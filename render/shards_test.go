@@ -0,0 +1,87 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestShardedRenders(t *testing.T) {
+	vars := map[string]string{
+		"IMAGE_NAME":  "app",
+		"BUILD_CMD":   "go build ./...",
+		"TEST_CMD":    "go test -race ./...",
+		"SONAR_HOST":  "sonar.example.com",
+		"TEST_SHARDS": "3",
+	}
+	shard := `pkgs=$(go list ./... | awk -v n=3 -v i="$TEST_SHARD" "(NR - 1) % n + 1 == i"); [ -z "$pkgs" ] || go test -race $pkgs`
+	for _, tc := range []struct {
+		name string
+		want []string
+	}{
+		{"github/workflow", []string{"shard: [ 1,2,3 ]", "TEST_SHARD: ${{ matrix.shard }}", "needs: [ build, lint, sonar, test ]"}},
+		{"gitlab/gitlab-ci", []string{"parallel: 3", "export TEST_SHARD=$CI_NODE_INDEX"}},
+		{"azure/azure-pipelines", []string{"parallel: 3", "TEST_SHARD: $(System.JobPositionInPhase)"}},
+		{"jenkins/Jenkinsfile", []string{"stage('Shard 1 of 3')", "stage('Shard 3 of 3')", "TEST_SHARD = '2'"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := RenderTemplateWith(tc.name, vars, RenderOptions{Verify: true})
+			if err != nil && !errors.Is(err, ErrUnknownVars) {
+				t.Fatalf("RenderTemplateWith: %v", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output lacks %q:\n%s", want, out)
+				}
+			}
+			if !strings.Contains(out, "go test -race $pkgs") {
+				t.Errorf("shard command missing:\n%s", out)
+			}
+
+			out, err = RenderTemplateWith(tc.name, MergeVars(vars, map[string]string{"TEST_SHARDS": "1"}), RenderOptions{Verify: true})
+			if err != nil && !errors.Is(err, ErrUnknownVars) {
+				t.Fatalf("RenderTemplateWith(TEST_SHARDS=1): %v", err)
+			}
+			if strings.Contains(out, "$pkgs") || !strings.Contains(out, "go test -race ./...") {
+				t.Errorf("TEST_SHARDS=1 sharded the tests:\n%s", out)
+			}
+		})
+	}
+	if got, _ := derivedVars(nil, vars); got["TEST_SHARD_CMD"] != shard {
+		t.Errorf("TEST_SHARD_CMD = %s, want %s", got["TEST_SHARD_CMD"], shard)
+	}
+}
+
+func TestShardingNeedsShardCmd(t *testing.T) {
+	vars := map[string]string{
+		"IMAGE_NAME":  "app",
+		"BUILD_CMD":   "go build ./...",
+		"TEST_CMD":    "make test",
+		"SONAR_HOST":  "sonar.example.com",
+		"TEST_SHARDS": "2",
+	}
+	_, err := RenderTemplate("github/workflow", vars)
+	if err == nil || !strings.Contains(err.Error(), "TEST_SHARDS needs a TEST_CMD ending in ./...") {
+		t.Errorf("RenderTemplate(TEST_CMD=make test) error = %v, want the TEST_SHARDS error", err)
+	}
+	if _, err := RenderTemplate("make/Makefile", vars); err != nil && !errors.Is(err, ErrUnknownVars) {
+		t.Errorf("a template that does not shard failed: %v", err)
+	}
+
+	vars["TEST_SHARD_CMD"] = `make test SHARD="$TEST_SHARD"`
+	out, err := RenderTemplate("github/workflow", vars)
+	if err != nil && !errors.Is(err, ErrUnknownVars) {
+		t.Fatalf("RenderTemplate with TEST_SHARD_CMD: %v", err)
+	}
+	if !strings.Contains(out, `make test SHARD="$TEST_SHARD"`) {
+		t.Errorf("explicit TEST_SHARD_CMD not used:\n%s", out)
+	}
+}
+
+func TestTestShardsMustBePositive(t *testing.T) {
+	for _, v := range []string{"0", "-2", "two"} {
+		if _, err := derivedVars(nil, map[string]string{"TEST_SHARDS": v}); err == nil {
+			t.Errorf("TEST_SHARDS=%q accepted", v)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadVars reads a .yaml, .yml or .json variables file and flattens it into
+// the KEY: value form RenderTemplate expects. Nested keys are joined with
+// underscores and uppercased, so
+//
+//	sonar:
+//	  host: https://sonar.example.com
+//
+// and a top-level "sonar.host" key both become SONAR_HOST. Lists are joined
+// with commas.
+//
+// Environment overlays follow a vars.<env>.<ext> layout: loading
+// vars.prod.yaml first loads the base vars.yaml (or .yml, .json) from the
+// same directory, if there is one, and merges the prod values over it. A
+// directory path loads the base file inside it.
+func LoadVars(path string) (map[string]string, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("render: load vars: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if st.IsDir() {
+		dir = path
+	}
+	base, err := findBaseVars(dir)
+	if err != nil {
+		return nil, err
+	}
+	if st.IsDir() {
+		if base == "" {
+			return nil, fmt.Errorf("render: load vars: no vars.yaml, vars.yml or vars.json in %s", path)
+		}
+		return loadVarsFile(base)
+	}
+
+	overlay, err := loadVarsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(filepath.Base(path), ".")
+	if base == "" || len(parts) != 3 || parts[0] != "vars" {
+		return overlay, nil
+	}
+	baseVars, err := loadVarsFile(base)
+	if err != nil {
+		return nil, err
+	}
+	return MergeVars(baseVars, overlay), nil
+}
+
+// findBaseVars returns the vars.yaml, vars.yml or vars.json file in dir, or
+// "" when there is none.
+func findBaseVars(dir string) (string, error) {
+	for _, name := range []string{"vars.yaml", "vars.yml", "vars.json"} {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("render: load vars: %w", err)
+		}
+	}
+	return "", nil
+}
+
+// MergeVars returns a new map holding base with each overlay applied in
+// order, so later overlays win on conflicting keys. The inputs are not
+// modified.
+func MergeVars(base map[string]string, overlays ...map[string]string) map[string]string {
+	merged := make(map[string]string, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for _, o := range overlays {
+		for k, v := range o {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func loadVarsFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("render: load vars: %w", err)
+	}
+	return parseVars(path, b)
+}
+
+// parseVars flattens the contents of a vars file; path picks the format by
+// its extension and names the file in errors.
+func parseVars(path string, b []byte) (map[string]string, error) {
+	var err error
+
+	// Scalars are kept as written, so "go_version: 1.20" stays "1.20"
+	// instead of round-tripping through a float.
+	var raw any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		var node yaml.Node
+		if err = yaml.Unmarshal(b, &node); err == nil {
+			raw = fromNode(&node)
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		err = dec.Decode(&raw)
+	default:
+		return nil, fmt.Errorf("render: load vars %s: unsupported extension %q (want .yaml, .yml or .json)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render: parse vars %s: %w", path, err)
+	}
+
+	if _, ok := raw.(map[string]any); !ok && raw != nil {
+		return nil, fmt.Errorf("render: parse vars %s: top level must be a mapping", path)
+	}
+	vars := map[string]string{}
+	if raw != nil {
+		flatten(vars, "", raw)
+	}
+	return vars, nil
+}
+
+// fromNode converts a YAML document into maps, slices and the literal
+// scalar strings.
+func fromNode(n *yaml.Node) any {
+	switch n.Kind {
+	case 0: // an empty file
+		return nil
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return fromNode(n.Content[0])
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			m[n.Content[i].Value] = fromNode(n.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		s := make([]any, len(n.Content))
+		for i, c := range n.Content {
+			s[i] = fromNode(c)
+		}
+		return s
+	case yaml.AliasNode:
+		return fromNode(n.Alias)
+	}
+	if n.Tag == "!!null" {
+		return nil
+	}
+	return n.Value
+}
+
+func flatten(dst map[string]string, prefix string, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			flatten(dst, joinKey(prefix, k), child)
+		}
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = scalar(item)
+		}
+		dst[prefix] = strings.Join(parts, ",")
+	default:
+		dst[prefix] = scalar(v)
+	}
+}
+
+func joinKey(prefix, key string) string {
+	key = strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
+
+func scalar(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,167 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeVars writes each file under dir, creating it.
+func writeVars(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadVars(t *testing.T) {
+	for _, tc := range []struct {
+		name, file, content string
+		want                map[string]string
+	}{
+		{
+			name:    "nested keys",
+			file:    "vars.yaml",
+			content: "sonar:\n  host: https://sonar.example.com\n  project.key: app\n",
+			want:    map[string]string{"SONAR_HOST": "https://sonar.example.com", "SONAR_PROJECT_KEY": "app"},
+		},
+		{
+			name:    "dotted key",
+			file:    "vars.yml",
+			content: "sonar.host: https://sonar.example.com\n",
+			want:    map[string]string{"SONAR_HOST": "https://sonar.example.com"},
+		},
+		{
+			name:    "scalars as written",
+			file:    "vars.yaml",
+			content: "go_version: 1.20\nreplicas: 3\nlint_enforce: yes\nempty:\n",
+			want:    map[string]string{"GO_VERSION": "1.20", "REPLICAS": "3", "LINT_ENFORCE": "yes", "EMPTY": ""},
+		},
+		{
+			name:    "lists joined",
+			file:    "vars.yaml",
+			content: "platforms:\n  - linux/amd64\n  - linux/arm64\n",
+			want:    map[string]string{"PLATFORMS": "linux/amd64,linux/arm64"},
+		},
+		{
+			name:    "json numbers",
+			file:    "vars.json",
+			content: `{"go_version": "1.22", "replicas": 3, "ratio": 1.50, "big": 12345678901234567890, "platforms": ["linux/amd64", "linux/arm64"]}`,
+			want:    map[string]string{"GO_VERSION": "1.22", "REPLICAS": "3", "RATIO": "1.50", "BIG": "12345678901234567890", "PLATFORMS": "linux/amd64,linux/arm64"},
+		},
+		{
+			name:    "empty file",
+			file:    "vars.yaml",
+			content: "",
+			want:    map[string]string{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeVars(t, dir, map[string]string{tc.file: tc.content})
+			got, err := LoadVars(filepath.Join(dir, tc.file))
+			if err != nil {
+				t.Fatalf("LoadVars: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("LoadVars() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name, file, content, wantErr string
+	}{
+		{"unsupported extension", "vars.toml", "a = 1\n", `unsupported extension ".toml"`},
+		{"top level list", "vars.yaml", "- a\n", "top level must be a mapping"},
+		{"bad yaml", "vars.yaml", "a: [\n", "parse vars"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeVars(t, dir, map[string]string{tc.file: tc.content})
+			_, err := LoadVars(filepath.Join(dir, tc.file))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LoadVars() error = %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadVarsOverlay(t *testing.T) {
+	dir := t.TempDir()
+	writeVars(t, dir, map[string]string{
+		"vars.yaml":      "image_name: app\nsonar:\n  host: https://sonar.dev\nreplicas: 1\n",
+		"vars.prod.yaml": "sonar:\n  host: https://sonar.prod\nreplicas: 3\n",
+		"other.yaml":     "replicas: 5\n",
+	})
+
+	got, err := LoadVars(filepath.Join(dir, "vars.prod.yaml"))
+	if err != nil {
+		t.Fatalf("LoadVars(vars.prod.yaml): %v", err)
+	}
+	want := map[string]string{"IMAGE_NAME": "app", "SONAR_HOST": "https://sonar.prod", "REPLICAS": "3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadVars(vars.prod.yaml) = %v, want %v", got, want)
+	}
+
+	got, err = LoadVars(dir)
+	if err != nil {
+		t.Fatalf("LoadVars(dir): %v", err)
+	}
+	want = map[string]string{"IMAGE_NAME": "app", "SONAR_HOST": "https://sonar.dev", "REPLICAS": "1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadVars(dir) = %v, want %v", got, want)
+	}
+
+	// A file outside the vars.<env> layout is not layered.
+	got, err = LoadVars(filepath.Join(dir, "other.yaml"))
+	if err != nil {
+		t.Fatalf("LoadVars(other.yaml): %v", err)
+	}
+	if want := map[string]string{"REPLICAS": "5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadVars(other.yaml) = %v, want %v", got, want)
+	}
+}
+
+func TestLoadVarsWithoutBase(t *testing.T) {
+	dir := t.TempDir()
+	writeVars(t, dir, map[string]string{"vars.prod.json": `{"replicas": 3}`})
+
+	got, err := LoadVars(filepath.Join(dir, "vars.prod.json"))
+	if err != nil {
+		t.Fatalf("LoadVars(vars.prod.json): %v", err)
+	}
+	if want := map[string]string{"REPLICAS": "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadVars(vars.prod.json) = %v, want %v", got, want)
+	}
+	if _, err := LoadVars(dir); err == nil || !strings.Contains(err.Error(), "no vars.yaml") {
+		t.Errorf("LoadVars(dir without vars.yaml) error = %v, want a no vars.yaml error", err)
+	}
+	if _, err := LoadVars(filepath.Join(dir, "vars.yaml")); err == nil {
+		t.Error("LoadVars(missing file) succeeded")
+	}
+}
+
+func TestMergeVarsLeavesInputs(t *testing.T) {
+	base := map[string]string{"A": "1", "B": "1"}
+	o1 := map[string]string{"B": "2", "C": "2"}
+	o2 := map[string]string{"C": "3"}
+
+	got := MergeVars(base, o1, o2)
+	if want := map[string]string{"A": "1", "B": "2", "C": "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeVars() = %v, want %v", got, want)
+	}
+	got["A"] = "changed"
+	if !reflect.DeepEqual(base, map[string]string{"A": "1", "B": "1"}) ||
+		!reflect.DeepEqual(o1, map[string]string{"B": "2", "C": "2"}) ||
+		!reflect.DeepEqual(o2, map[string]string{"C": "3"}) {
+		t.Errorf("MergeVars modified its inputs: %v %v %v", base, o1, o2)
+	}
+	if got := MergeVars(nil); got == nil || len(got) != 0 {
+		t.Errorf("MergeVars(nil) = %v, want an empty map", got)
+	}
+}
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VerifyRendered checks that content, the rendered output of the named
+// template, is still well formed after substitution: YAML templates must
+// parse and a Jenkinsfile must balance its braces, brackets and parentheses.
+// Other templates, and Helm chart templates that are not YAML until Helm
+// renders them, always pass. Problems are returned as a *ValidationError;
+// a YAML error names its line and suggests quoting the value there.
+//
+// RenderOptions.Verify runs the same check on every render, and can then
+// also name the variable whose value broke the file.
+func VerifyRendered(name, content string) error {
+	e, ok := std.lookup(name)
+	if !ok {
+		return &UnknownTemplateError{Name: name}
+	}
+	if issues := verifyOutput(e, content, nil); len(issues) > 0 {
+		return &ValidationError{Template: name, Issues: issues}
+	}
+	return nil
+}
+
+// verifyOutput returns the syntax issues in e's rendered output. vars, when
+// given, are searched for a value that could explain a YAML error.
+func verifyOutput(e entry, content string, vars map[string]string) []string {
+	switch base := path.Base(e.out); {
+	case base == "Jenkinsfile":
+		return checkBrackets(content)
+	case strings.HasPrefix(e.out, "templates/"):
+		// Helm template; not YAML until Helm renders it.
+	case strings.HasSuffix(base, ".yml") || strings.HasSuffix(base, ".yaml"):
+		var doc any
+		if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+			return []string{"invalid YAML: " + err.Error() + quoteHint(content, err, vars)}
+		}
+	}
+	return nil
+}
+
+// yamlErrLine finds the line number in a yaml.v3 error message.
+var yamlErrLine = regexp.MustCompile(`line (\d+)`)
+
+// yamlSpecial holds the characters that change the meaning of a plain YAML
+// scalar.
+const yamlSpecial = ":#{}[],&*!|>'\"%@`"
+
+// quoteHint suggests quoting the value on the line a YAML error points at,
+// naming the variables in vars whose values appear there and contain
+// characters YAML treats specially.
+func quoteHint(content string, err error, vars map[string]string) string {
+	m := yamlErrLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return ""
+	}
+	n, _ := strconv.Atoi(m[1])
+	lines := strings.Split(content, "\n")
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	line := lines[n-1]
+	var suspects []string
+	for k, v := range vars {
+		if v != "" && strings.ContainsAny(v, yamlSpecial) && strings.Contains(line, v) {
+			suspects = append(suspects, k)
+		}
+	}
+	if len(suspects) == 0 {
+		return fmt.Sprintf(" (a value on line %d may need quoting: %s)", n, strings.TrimSpace(line))
+	}
+	sort.Strings(suspects)
+	return fmt.Sprintf(" (quote the value of %s on line %d)", strings.Join(suspects, ", "), n)
+}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ErrNoVersion is returned when a template or generated file carries no
+// version marker.
+var ErrNoVersion = errors.New("no hhclub version marker")
+
+// generatedMarker is what a {{! version: N }} tag renders to. Templates put
+// the tag inside a comment in their own syntax, so the marker survives in
+// the generated file without breaking it.
+var generatedMarker = regexp.MustCompile(`hhclub:version (\d+)`)
+
+// versionTag matches a {{! version: N }} tag and captures N.
+func (d delims) versionTag() *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(d.left) + `\s*!\s*version:\s*(\d+)\s*` + regexp.QuoteMeta(d.right))
+}
+
+func expandVersion(src string, d delims) string {
+	return d.versionTag().ReplaceAllString(src, "hhclub:version $1")
+}
+
+// TemplateVersion returns the version declared by the {{! version: N }}
+// marker at the top of the named template. It is bumped whenever the
+// template's output changes.
+func TemplateVersion(name string) (int, error) {
+	_, d, src, err := std.load(name, RenderOptions{})
+	if err != nil {
+		return 0, err
+	}
+	m := d.versionTag().FindStringSubmatch(src)
+	if m == nil {
+		return 0, fmt.Errorf("render: %s: %w", name, ErrNoVersion)
+	}
+	return strconv.Atoi(m[1])
+}
+
+// GeneratedVersion reads the template version recorded in a previously
+// generated file, so a tool can tell a user their Dockerfile came from v2
+// while TemplateVersion reports v4.
+func GeneratedVersion(content string) (int, error) {
+	m := generatedMarker.FindStringSubmatch(content)
+	if m == nil {
+		return 0, ErrNoVersion
+	}
+	return strconv.Atoi(m[1])
+}
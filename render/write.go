@@ -0,0 +1,105 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteTemplate renders the named template and writes it to outPath,
+// creating parent directories as needed. The file is written atomically
+// through a temporary file, with mode 0755 for script templates and 0644
+// otherwise. An existing outPath is left untouched and reported as
+// fs.ErrExist unless overwrite is set, even one created while the file is
+// being written: the temporary file is then published with a hard link,
+// which refuses to replace anything, rather than a rename. On a file system
+// without hard links the file is instead created exclusively and written in
+// place.
+//
+// Render errors are returned as from RenderTemplate; an unknown-variables
+// warning does not stop the write.
+func WriteTemplate(name, outPath string, vars map[string]string, overwrite bool) error {
+	out, err := RenderTemplate(name, vars)
+	if !rendered(err) {
+		return err
+	}
+	e, _ := std.lookup(name)
+	if werr := writeFile(outPath, []byte(out), e.mode(), overwrite); werr != nil {
+		return werr
+	}
+	return err
+}
+
+func writeFile(path string, data []byte, perm fs.FileMode, overwrite bool) error {
+	if !overwrite {
+		if _, err := os.Lstat(path); err == nil {
+			return fmt.Errorf("render: write %s: %w", path, fs.ErrExist)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("render: write %s: %w", path, err)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("render: write %s: %w", path, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("render: write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("render: write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("render: write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("render: write %s: %w", path, err)
+	}
+	if overwrite {
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return fmt.Errorf("render: write %s: %w", path, err)
+		}
+		return nil
+	}
+	// Unlike a rename, a link fails if path appeared since the check above,
+	// so a file created in between is never replaced.
+	err = link(tmp.Name(), path)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		err = createExclusive(path, data, perm)
+	}
+	if err != nil {
+		return fmt.Errorf("render: write %s: %w", path, err)
+	}
+	return nil
+}
+
+// link is os.Link, replaced in tests.
+var link = os.Link
+
+// createExclusive writes data to a new file at path, failing with
+// fs.ErrExist if there already is one. A reader may see the file half
+// written; a failed write removes it.
+func createExclusive(path string, data []byte, perm fs.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
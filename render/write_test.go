@@ -0,0 +1,132 @@
+package render
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	vars := map[string]string{"BUILD_CMD": "go build ./cmd/api"}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "nested", "dir", "Makefile")
+
+	if err := WriteTemplate("make/Makefile", out, vars, false); err != nil {
+		t.Fatalf("WriteTemplate: %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("output not written: %v", err)
+	}
+	if !strings.Contains(string(b), "go build ./cmd/api") {
+		t.Errorf("unexpected output:\n%s", b)
+	}
+	checkMode(t, out, 0o644)
+	checkOnlyFile(t, filepath.Dir(out))
+
+	// An existing file is kept unless overwrite is set.
+	if err := os.WriteFile(out, []byte("mine\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteTemplate("make/Makefile", out, vars, false); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("WriteTemplate over an existing file = %v, want fs.ErrExist", err)
+	}
+	if b, _ := os.ReadFile(out); string(b) != "mine\n" {
+		t.Errorf("existing file replaced:\n%s", b)
+	}
+	checkOnlyFile(t, filepath.Dir(out))
+
+	if err := WriteTemplate("make/Makefile", out, vars, true); err != nil {
+		t.Fatalf("WriteTemplate(overwrite): %v", err)
+	}
+	if b, _ := os.ReadFile(out); !strings.Contains(string(b), "go build ./cmd/api") {
+		t.Errorf("overwrite did not replace the file:\n%s", b)
+	}
+	checkMode(t, out, 0o644)
+	checkOnlyFile(t, filepath.Dir(out))
+}
+
+func TestWriteFileWithoutHardLinks(t *testing.T) {
+	link = func(string, string) error { return &os.LinkError{Op: "link", Err: errors.ErrUnsupported} }
+	t.Cleanup(func() { link = os.Link })
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "run.sh")
+	if err := writeFile(out, []byte("#!/bin/sh\n"), 0o755, false); err != nil {
+		t.Fatalf("writeFile: %v", err)
+	}
+	if b, _ := os.ReadFile(out); string(b) != "#!/bin/sh\n" {
+		t.Errorf("unexpected content %q", b)
+	}
+	checkMode(t, out, 0o755)
+	checkOnlyFile(t, dir)
+
+	if err := createExclusive(out, []byte("again\n"), 0o644); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("createExclusive over an existing file = %v, want fs.ErrExist", err)
+	}
+	if b, _ := os.ReadFile(out); string(b) != "#!/bin/sh\n" {
+		t.Errorf("existing file replaced: %q", b)
+	}
+}
+
+func checkMode(t *testing.T, path string, want fs.FileMode) {
+	t.Helper()
+	st, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := st.Mode().Perm(); got != want {
+		t.Errorf("%s mode = %v, want %v", path, got, want)
+	}
+}
+
+// checkOnlyFile fails unless dir holds a single file, so no temporary file
+// was left behind.
+func checkOnlyFile(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("%s holds %v, want one file", dir, names)
+	}
+}
+
+func TestEmptyOutputIsWritten(t *testing.T) {
+	saved := std
+	std = NewRenderer(OverlayFS(fstest.MapFS{"extra/empty.tmpl": {Data: []byte("{{#if ON}}\non\n{{/if}}\n")}}, Embedded()))
+	t.Cleanup(func() { std = saved })
+
+	dir := t.TempDir()
+	written, err := RenderAll(dir, []string{"extra/empty"}, nil)
+	if err != nil {
+		t.Fatalf("RenderAll: %v", err)
+	}
+	if want := []string{filepath.Join(dir, "empty"), filepath.Join(dir, ".gitignore")}; !reflect.DeepEqual(written, want) {
+		t.Errorf("RenderAll wrote %v, want %v", written, want)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "empty")); err != nil || len(b) != 0 {
+		t.Errorf("empty output = %q, %v; want an empty file", b, err)
+	}
+
+	out := filepath.Join(dir, "again")
+	if err := WriteTemplate("extra/empty", out, map[string]string{"TYPO": "x"}, false); !errors.Is(err, ErrUnknownVars) {
+		t.Errorf("WriteTemplate = %v, want an ErrUnknownVars warning", err)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("empty output not written: %v", err)
+	}
+	if d, err := DiffTemplate("extra/empty", out, nil); err != nil || d != "" {
+		t.Errorf("DiffTemplate = %q, %v; want no diff", d, err)
+	}
+}
@@ -0,0 +1,24 @@
+// Package hhclub embeds the Dockerfile and CI/CD templates shipped with this
+// repository. Use the render package to fill in their placeholders.
+package hhclub
+
+import (
+	"embed"
+	"path"
+)
+
+// FS holds the templates directory as shipped. Paths inside it keep the
+// "templates/" prefix; use Template to read a file relative to it.
+//
+//go:embed templates
+var FS embed.FS
+
+// Template returns the raw contents of a template file. name is relative to
+// the templates directory and laid out as <category>/<file>.tmpl, e.g.
+// "docker/Dockerfile.tmpl" or "jenkins/Jenkinsfile.tmpl". The category
+// directory names the target tool and is what render reports as a
+// template's category. Files whose names start with "." or "_" are not
+// embedded.
+func Template(name string) ([]byte, error) {
+	return FS.ReadFile(path.Join("templates", name))
+}